@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codeBadGateway = "bad_gateway"
+
+type ErrBadGateway struct {
+	Base
+}
+
+func NewBadGateway(err error) *ErrBadGateway {
+	return &ErrBadGateway{
+		Base: newBase(err, http.StatusText(http.StatusBadGateway), codeBadGateway),
+	}
+}
+
+func (*ErrBadGateway) BadGateway() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrBadGateway) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrBadGateway) WithMessage(message string) *ErrBadGateway {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrBadGateway) WithCode(code string) *ErrBadGateway {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrBadGateway) WithMessageKey(key string, params map[string]interface{}) *ErrBadGateway {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrBadGateway) WithDetail(key string, value interface{}) *ErrBadGateway {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a bad gateway error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrBadGateway) Is(target error) bool {
+	t, ok := target.(*ErrBadGateway)
+	return ok && e.is(&t.Base, codeBadGateway)
+}
@@ -1,26 +1,28 @@
 package ferrors
 
 import (
+	"errors"
 	"net/http"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+const codeBadRequest = "bad_request"
+
 type ErrBadRequest struct {
-	error
-	message string
-	fields  map[string]error
+	Base
+	fields map[string]error
 }
 
 func NewBadRequest(err error) *ErrBadRequest {
 	var fields map[string]error
-	if e, ok := err.(validation.Errors); ok {
-		fields = e
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		fields = errs
 	}
 	return &ErrBadRequest{
-		error:   err,
-		message: http.StatusText(http.StatusBadRequest),
-		fields:  fields,
+		Base:   newBase(err, http.StatusText(http.StatusBadRequest), codeBadRequest),
+		fields: fields,
 	}
 }
 
@@ -28,6 +30,42 @@ func (*ErrBadRequest) BadRequest() bool {
 	return true
 }
 
+// Cause implements the pkg/errors interface.
+func (e *ErrBadRequest) Cause() error { return e.cause() }
+
 func (e *ErrBadRequest) GetFields() map[string]error {
 	return e.fields
 }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrBadRequest) WithMessage(message string) *ErrBadRequest {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrBadRequest) WithCode(code string) *ErrBadRequest {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrBadRequest) WithMessageKey(key string, params map[string]interface{}) *ErrBadRequest {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrBadRequest) WithDetail(key string, value interface{}) *ErrBadRequest {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a bad request error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrBadRequest) Is(target error) bool {
+	t, ok := target.(*ErrBadRequest)
+	return ok && e.is(&t.Base, codeBadRequest)
+}
@@ -1,26 +1,28 @@
 package ferrors
 
 import (
+	"errors"
 	"net/http"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+const codeConflict = "conflict"
+
 type ErrConflict struct {
-	error
-	message string
-	fields  map[string]error
+	Base
+	fields map[string]error
 }
 
 func NewConflict(err error) *ErrConflict {
 	var fields map[string]error
-	if e, ok := err.(validation.Errors); ok {
-		fields = e
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		fields = errs
 	}
 	return &ErrConflict{
-		error:   err,
-		message: http.StatusText(http.StatusConflict),
-		fields:  fields,
+		Base:   newBase(err, http.StatusText(http.StatusConflict), codeConflict),
+		fields: fields,
 	}
 }
 
@@ -28,6 +30,42 @@ func (*ErrConflict) Conflict() bool {
 	return true
 }
 
+// Cause implements the pkg/errors interface.
+func (e *ErrConflict) Cause() error { return e.cause() }
+
 func (e *ErrConflict) GetFields() map[string]error {
 	return e.fields
 }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrConflict) WithMessage(message string) *ErrConflict {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrConflict) WithCode(code string) *ErrConflict {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrConflict) WithMessageKey(key string, params map[string]interface{}) *ErrConflict {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrConflict) WithDetail(key string, value interface{}) *ErrConflict {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a conflict error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrConflict) Is(target error) bool {
+	t, ok := target.(*ErrConflict)
+	return ok && e.is(&t.Base, codeConflict)
+}
@@ -0,0 +1,124 @@
+package ferrors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// Base is the core shared by every error kind. It keeps the cause,
+// a user facing message, a machine readable code, details and the stack trace.
+type Base struct {
+	error
+
+	message string
+	code    string
+	details map[string]interface{}
+	stack   []uintptr
+
+	key    string
+	params map[string]interface{}
+}
+
+// newBase captures the stack of the caller of the kind constructor.
+func newBase(err error, message, code string) Base {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	return Base{
+		error:   err,
+		message: message,
+		code:    code,
+		stack:   pcs[:n],
+	}
+}
+
+// Error returns the message of the cause, or the user facing message
+// when there is no cause.
+func (e *Base) Error() string {
+	if e.error == nil {
+		return e.message
+	}
+	return e.error.Error()
+}
+
+// Msg returns the user facing message.
+func (e *Base) Msg() string { return e.message }
+
+// Code returns the machine readable error code.
+func (e *Base) Code() string { return e.code }
+
+// Details returns the details added to the error.
+func (e *Base) Details() map[string]interface{} { return e.details }
+
+// MessageKey returns the catalog key of the user facing message and its params.
+// The key is empty when the message is not translatable.
+func (e *Base) MessageKey() (string, map[string]interface{}) { return e.key, e.params }
+
+// cause returns the cause, for the Cause method of the kinds, or the error
+// itself, without a Cause method, when there is none, so pkg/errors.Cause
+// returns the error instead of nil. ErrMulti has no Cause method at all.
+func (e *Base) cause() error {
+	if e.error == nil {
+		return causeless{e}
+	}
+	return e.error
+}
+
+// causeless is an error without a cause, as seen by pkg/errors.Cause.
+type causeless struct{ *Base }
+
+// Unwrap implements the standard errors interface.
+func (e *Base) Unwrap() error { return e.error }
+
+func (e *Base) setDetail(key string, value interface{}) {
+	if e.details == nil {
+		e.details = make(map[string]interface{})
+	}
+	e.details[key] = value
+}
+
+// is reports whether target has the same code as e,
+// or a code that does not narrow its kind.
+func (e *Base) is(target *Base, kindCode string) bool {
+	return target.code == "" || target.code == kindCode || target.code == e.code
+}
+
+// StackTrace implements the pkg/errors stack trace interface.
+// The stack of the cause is preferred, since it is closer to the origin of the error.
+func (e *Base) StackTrace() errors.StackTrace {
+	type stackTracer interface {
+		StackTrace() errors.StackTrace
+	}
+	if st, ok := e.error.(stackTracer); ok {
+		return st.StackTrace()
+	}
+
+	frames := make(errors.StackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		frames[i] = errors.Frame(pc)
+	}
+	return frames
+}
+
+// Format implements the fmt.Formatter.
+// %s and %v print the error message, %q the quoted message
+// and %+v the message followed by the stack trace.
+func (e *Base) Format(st fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if st.Flag('+') {
+			_, _ = io.WriteString(st, e.Error())
+			for _, f := range e.StackTrace() {
+				fmt.Fprintf(st, "\n%+v", f)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(st, e.Error())
+	case 'q':
+		fmt.Fprintf(st, "%q", e.Error())
+	}
+}
@@ -1,21 +1,55 @@
 package ferrors
 
-import (
-	"net/http"
-)
+import "net/http"
+
+const codeForbidden = "forbidden"
 
 type ErrForbidden struct {
-	error
-	message string
+	Base
 }
 
 func NewForbidden(err error) *ErrForbidden {
 	return &ErrForbidden{
-		error:   err,
-		message: http.StatusText(http.StatusForbidden),
+		Base: newBase(err, http.StatusText(http.StatusForbidden), codeForbidden),
 	}
 }
 
 func (*ErrForbidden) Forbidden() bool {
 	return true
 }
+
+// Cause implements the pkg/errors interface.
+func (e *ErrForbidden) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrForbidden) WithMessage(message string) *ErrForbidden {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrForbidden) WithCode(code string) *ErrForbidden {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrForbidden) WithMessageKey(key string, params map[string]interface{}) *ErrForbidden {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrForbidden) WithDetail(key string, value interface{}) *ErrForbidden {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a forbidden error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrForbidden) Is(target error) bool {
+	t, ok := target.(*ErrForbidden)
+	return ok && e.is(&t.Base, codeForbidden)
+}
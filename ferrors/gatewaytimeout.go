@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codeGatewayTimeout = "gateway_timeout"
+
+type ErrGatewayTimeout struct {
+	Base
+}
+
+func NewGatewayTimeout(err error) *ErrGatewayTimeout {
+	return &ErrGatewayTimeout{
+		Base: newBase(err, http.StatusText(http.StatusGatewayTimeout), codeGatewayTimeout),
+	}
+}
+
+func (*ErrGatewayTimeout) GatewayTimeout() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrGatewayTimeout) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrGatewayTimeout) WithMessage(message string) *ErrGatewayTimeout {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrGatewayTimeout) WithCode(code string) *ErrGatewayTimeout {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrGatewayTimeout) WithMessageKey(key string, params map[string]interface{}) *ErrGatewayTimeout {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrGatewayTimeout) WithDetail(key string, value interface{}) *ErrGatewayTimeout {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a gateway timeout error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrGatewayTimeout) Is(target error) bool {
+	t, ok := target.(*ErrGatewayTimeout)
+	return ok && e.is(&t.Base, codeGatewayTimeout)
+}
@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codeGone = "gone"
+
+type ErrGone struct {
+	Base
+}
+
+func NewGone(err error) *ErrGone {
+	return &ErrGone{
+		Base: newBase(err, http.StatusText(http.StatusGone), codeGone),
+	}
+}
+
+func (*ErrGone) Gone() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrGone) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrGone) WithMessage(message string) *ErrGone {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrGone) WithCode(code string) *ErrGone {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrGone) WithMessageKey(key string, params map[string]interface{}) *ErrGone {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrGone) WithDetail(key string, value interface{}) *ErrGone {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a gone error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrGone) Is(target error) bool {
+	t, ok := target.(*ErrGone)
+	return ok && e.is(&t.Base, codeGone)
+}
@@ -2,18 +2,54 @@ package ferrors
 
 import "net/http"
 
+const codeInternalServer = "internal_server"
+
 type ErrInternalServer struct {
-	error
-	message string
+	Base
 }
 
 func NewInternalServer(err error) *ErrInternalServer {
 	return &ErrInternalServer{
-		error:   err,
-		message: http.StatusText(http.StatusInternalServerError),
+		Base: newBase(err, http.StatusText(http.StatusInternalServerError), codeInternalServer),
 	}
 }
 
 func (*ErrInternalServer) InternalServer() bool {
 	return true
 }
+
+// Cause implements the pkg/errors interface.
+func (e *ErrInternalServer) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrInternalServer) WithMessage(message string) *ErrInternalServer {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrInternalServer) WithCode(code string) *ErrInternalServer {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrInternalServer) WithMessageKey(key string, params map[string]interface{}) *ErrInternalServer {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrInternalServer) WithDetail(key string, value interface{}) *ErrInternalServer {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is an internal server error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrInternalServer) Is(target error) bool {
+	t, ok := target.(*ErrInternalServer)
+	return ok && e.is(&t.Base, codeInternalServer)
+}
@@ -0,0 +1,160 @@
+package ferrors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const codeMultiStatus = "multi_status"
+
+// ItemError is the error of an item of a batch, identified by its path,
+// such as an index of the batch or a path like "items[3].email".
+type ItemError struct {
+	Path string
+	Err  error
+}
+
+// ErrMulti aggregates the errors of independent items of a batch.
+// Its overall status is 207 Multi-Status when some items succeeded,
+// or the most severe status of its items otherwise. Unless a message is set,
+// its user facing message is empty, so that of the overall status is used.
+type ErrMulti struct {
+	Base
+	items   []ItemError
+	partial bool
+}
+
+func NewMulti() *ErrMulti {
+	return &ErrMulti{
+		Base: newBase(nil, "", codeMultiStatus),
+	}
+}
+
+func (*ErrMulti) Multi() bool {
+	return true
+}
+
+// Add returns the error after adding the error of the item at path.
+// Nil errors are ignored.
+func (e *ErrMulti) Add(path string, err error) *ErrMulti {
+	if err != nil {
+		e.items = append(e.items, ItemError{Path: path, Err: err})
+	}
+	return e
+}
+
+// AddIndex returns the error after adding the error of the item at index.
+// Nil errors are ignored.
+func (e *ErrMulti) AddIndex(index int, err error) *ErrMulti {
+	return e.Add(strconv.Itoa(index), err)
+}
+
+// Items returns the item errors, in the order they were added.
+func (e *ErrMulti) Items() []ItemError {
+	return e.items
+}
+
+// Len returns the number of item errors.
+func (e *ErrMulti) Len() int {
+	return len(e.items)
+}
+
+// ErrorOrNil returns nil when there is no item error, and the error otherwise.
+// Returning it avoids a non nil error interface holding a nil *ErrMulti.
+func (e *ErrMulti) ErrorOrNil() error {
+	if e == nil || len(e.items) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Partial reports whether some items of the batch succeeded.
+func (e *ErrMulti) Partial() bool {
+	return e.partial
+}
+
+// WithPartialSuccess returns the error after marking that some items
+// of the batch succeeded, so its overall status is 207 Multi-Status.
+func (e *ErrMulti) WithPartialSuccess() *ErrMulti {
+	e.partial = true
+	return e
+}
+
+// Error returns the messages of the item errors, prefixed by their paths.
+func (e *ErrMulti) Error() string {
+	if len(e.items) == 0 {
+		if e.message == "" {
+			return http.StatusText(http.StatusMultiStatus)
+		}
+		return e.message
+	}
+	msgs := make([]string, len(e.items))
+	for i, item := range e.items {
+		msgs[i] = item.Path + ": " + item.Err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Format implements the fmt.Formatter, like Base does, with the messages of the items.
+func (e *ErrMulti) Format(st fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if st.Flag('+') {
+			_, _ = io.WriteString(st, e.Error())
+			for _, item := range e.items {
+				fmt.Fprintf(st, "\n%s: %+v", item.Path, item.Err)
+			}
+			return
+		}
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(st, e.Error())
+	case 'q':
+		fmt.Fprintf(st, "%q", e.Error())
+	}
+}
+
+// Unwrap returns the item errors, so errors.Is and errors.As look into them.
+func (e *ErrMulti) Unwrap() []error {
+	errs := make([]error, len(e.items))
+	for i, item := range e.items {
+		errs[i] = item.Err
+	}
+	return errs
+}
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrMulti) WithMessage(message string) *ErrMulti {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrMulti) WithCode(code string) *ErrMulti {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrMulti) WithMessageKey(key string, params map[string]interface{}) *ErrMulti {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrMulti) WithDetail(key string, value interface{}) *ErrMulti {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a multi error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrMulti) Is(target error) bool {
+	t, ok := target.(*ErrMulti)
+	return ok && e.is(&t.Base, codeMultiStatus)
+}
@@ -2,18 +2,54 @@ package ferrors
 
 import "net/http"
 
+const codeNotAcceptable = "not_acceptable"
+
 type ErrNotAcceptable struct {
-	error
-	message string
+	Base
 }
 
 func NewNotAcceptable(err error) *ErrNotAcceptable {
 	return &ErrNotAcceptable{
-		error:   err,
-		message: http.StatusText(http.StatusNotAcceptable),
+		Base: newBase(err, http.StatusText(http.StatusNotAcceptable), codeNotAcceptable),
 	}
 }
 
 func (*ErrNotAcceptable) NotAcceptable() bool {
 	return true
 }
+
+// Cause implements the pkg/errors interface.
+func (e *ErrNotAcceptable) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrNotAcceptable) WithMessage(message string) *ErrNotAcceptable {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrNotAcceptable) WithCode(code string) *ErrNotAcceptable {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrNotAcceptable) WithMessageKey(key string, params map[string]interface{}) *ErrNotAcceptable {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrNotAcceptable) WithDetail(key string, value interface{}) *ErrNotAcceptable {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a not acceptable error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrNotAcceptable) Is(target error) bool {
+	t, ok := target.(*ErrNotAcceptable)
+	return ok && e.is(&t.Base, codeNotAcceptable)
+}
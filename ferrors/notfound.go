@@ -2,18 +2,54 @@ package ferrors
 
 import "net/http"
 
+const codeNotFound = "not_found"
+
 type ErrNotFound struct {
-	error
-	message string
+	Base
 }
 
 func NewNotFound(err error) *ErrNotFound {
 	return &ErrNotFound{
-		error:   err,
-		message: http.StatusText(http.StatusNotFound),
+		Base: newBase(err, http.StatusText(http.StatusNotFound), codeNotFound),
 	}
 }
 
 func (*ErrNotFound) NotFound() bool {
 	return true
 }
+
+// Cause implements the pkg/errors interface.
+func (e *ErrNotFound) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrNotFound) WithMessage(message string) *ErrNotFound {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrNotFound) WithCode(code string) *ErrNotFound {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrNotFound) WithMessageKey(key string, params map[string]interface{}) *ErrNotFound {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrNotFound) WithDetail(key string, value interface{}) *ErrNotFound {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a not found error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrNotFound) Is(target error) bool {
+	t, ok := target.(*ErrNotFound)
+	return ok && e.is(&t.Base, codeNotFound)
+}
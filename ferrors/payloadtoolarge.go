@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codePayloadTooLarge = "payload_too_large"
+
+type ErrPayloadTooLarge struct {
+	Base
+}
+
+func NewPayloadTooLarge(err error) *ErrPayloadTooLarge {
+	return &ErrPayloadTooLarge{
+		Base: newBase(err, http.StatusText(http.StatusRequestEntityTooLarge), codePayloadTooLarge),
+	}
+}
+
+func (*ErrPayloadTooLarge) PayloadTooLarge() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrPayloadTooLarge) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrPayloadTooLarge) WithMessage(message string) *ErrPayloadTooLarge {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrPayloadTooLarge) WithCode(code string) *ErrPayloadTooLarge {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrPayloadTooLarge) WithMessageKey(key string, params map[string]interface{}) *ErrPayloadTooLarge {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrPayloadTooLarge) WithDetail(key string, value interface{}) *ErrPayloadTooLarge {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a payload too large error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrPayloadTooLarge) Is(target error) bool {
+	t, ok := target.(*ErrPayloadTooLarge)
+	return ok && e.is(&t.Base, codePayloadTooLarge)
+}
@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codePreconditionFailed = "precondition_failed"
+
+type ErrPreconditionFailed struct {
+	Base
+}
+
+func NewPreconditionFailed(err error) *ErrPreconditionFailed {
+	return &ErrPreconditionFailed{
+		Base: newBase(err, http.StatusText(http.StatusPreconditionFailed), codePreconditionFailed),
+	}
+}
+
+func (*ErrPreconditionFailed) PreconditionFailed() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrPreconditionFailed) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrPreconditionFailed) WithMessage(message string) *ErrPreconditionFailed {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrPreconditionFailed) WithCode(code string) *ErrPreconditionFailed {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrPreconditionFailed) WithMessageKey(key string, params map[string]interface{}) *ErrPreconditionFailed {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrPreconditionFailed) WithDetail(key string, value interface{}) *ErrPreconditionFailed {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a precondition failed error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrPreconditionFailed) Is(target error) bool {
+	t, ok := target.(*ErrPreconditionFailed)
+	return ok && e.is(&t.Base, codePreconditionFailed)
+}
@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codeRequestTimeout = "request_timeout"
+
+type ErrRequestTimeout struct {
+	Base
+}
+
+func NewRequestTimeout(err error) *ErrRequestTimeout {
+	return &ErrRequestTimeout{
+		Base: newBase(err, http.StatusText(http.StatusRequestTimeout), codeRequestTimeout),
+	}
+}
+
+func (*ErrRequestTimeout) RequestTimeout() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrRequestTimeout) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrRequestTimeout) WithMessage(message string) *ErrRequestTimeout {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrRequestTimeout) WithCode(code string) *ErrRequestTimeout {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrRequestTimeout) WithMessageKey(key string, params map[string]interface{}) *ErrRequestTimeout {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrRequestTimeout) WithDetail(key string, value interface{}) *ErrRequestTimeout {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a request timeout error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrRequestTimeout) Is(target error) bool {
+	t, ok := target.(*ErrRequestTimeout)
+	return ok && e.is(&t.Base, codeRequestTimeout)
+}
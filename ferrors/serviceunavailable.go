@@ -0,0 +1,72 @@
+package ferrors
+
+import (
+	"net/http"
+	"time"
+)
+
+const codeServiceUnavailable = "service_unavailable"
+
+type ErrServiceUnavailable struct {
+	Base
+	retryAfter time.Duration
+}
+
+func NewServiceUnavailable(err error) *ErrServiceUnavailable {
+	return &ErrServiceUnavailable{
+		Base: newBase(err, http.StatusText(http.StatusServiceUnavailable), codeServiceUnavailable),
+	}
+}
+
+func (*ErrServiceUnavailable) ServiceUnavailable() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrServiceUnavailable) Cause() error { return e.cause() }
+
+// RetryAfter returns how long the client should wait before retrying.
+// Zero means it was not set.
+func (e *ErrServiceUnavailable) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// WithRetryAfter returns the error after setting how long the client should wait,
+// sent in the Retry-After header.
+func (e *ErrServiceUnavailable) WithRetryAfter(d time.Duration) *ErrServiceUnavailable {
+	e.retryAfter = d
+	return e
+}
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrServiceUnavailable) WithMessage(message string) *ErrServiceUnavailable {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrServiceUnavailable) WithCode(code string) *ErrServiceUnavailable {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrServiceUnavailable) WithMessageKey(key string, params map[string]interface{}) *ErrServiceUnavailable {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrServiceUnavailable) WithDetail(key string, value interface{}) *ErrServiceUnavailable {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a service unavailable error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrServiceUnavailable) Is(target error) bool {
+	t, ok := target.(*ErrServiceUnavailable)
+	return ok && e.is(&t.Base, codeServiceUnavailable)
+}
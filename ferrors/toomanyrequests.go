@@ -0,0 +1,72 @@
+package ferrors
+
+import (
+	"net/http"
+	"time"
+)
+
+const codeTooManyRequests = "too_many_requests"
+
+type ErrTooManyRequests struct {
+	Base
+	retryAfter time.Duration
+}
+
+func NewTooManyRequests(err error) *ErrTooManyRequests {
+	return &ErrTooManyRequests{
+		Base: newBase(err, http.StatusText(http.StatusTooManyRequests), codeTooManyRequests),
+	}
+}
+
+func (*ErrTooManyRequests) TooManyRequests() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrTooManyRequests) Cause() error { return e.cause() }
+
+// RetryAfter returns how long the client should wait before retrying.
+// Zero means it was not set.
+func (e *ErrTooManyRequests) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// WithRetryAfter returns the error after setting how long the client should wait,
+// sent in the Retry-After header.
+func (e *ErrTooManyRequests) WithRetryAfter(d time.Duration) *ErrTooManyRequests {
+	e.retryAfter = d
+	return e
+}
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrTooManyRequests) WithMessage(message string) *ErrTooManyRequests {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrTooManyRequests) WithCode(code string) *ErrTooManyRequests {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrTooManyRequests) WithMessageKey(key string, params map[string]interface{}) *ErrTooManyRequests {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrTooManyRequests) WithDetail(key string, value interface{}) *ErrTooManyRequests {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is a too many requests error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrTooManyRequests) Is(target error) bool {
+	t, ok := target.(*ErrTooManyRequests)
+	return ok && e.is(&t.Base, codeTooManyRequests)
+}
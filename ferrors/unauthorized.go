@@ -1,37 +1,32 @@
 package ferrors
 
 import (
-	"fmt"
-	"io"
 	"net/http"
-
-	"github.com/pkg/errors"
 )
 
+const codeUnauthorized = "unauthorized"
+
 // ErrUnauthorized implements middleware's unauthorized and message interfaces.
 type ErrUnauthorized struct {
-	error
-
-	message string
+	Base
 }
 
 // NewUnauthorized returns a struct that implements go's error interface,
 // and middleware's unauthorized and message interfaces,
-// setting message to err.Error() string.
-// If err is nil a panic() will raise.
-// To have the stack trace, call this function with
-// errors.Wrap(), errors.Wrapf() or errors.WithStack().
+// setting message to the unauthorized status text.
+// The stack trace is captured here, unless err already carries one,
+// as when it is created by errors.Wrap(), errors.Wrapf() or errors.WithStack().
 // Example: NewUnauthorized(errors.WithStack(err))
 func NewUnauthorized(err error) *ErrUnauthorized {
-	return &ErrUnauthorized{message: http.StatusText(http.StatusUnauthorized), error: err}
+	return &ErrUnauthorized{Base: newBase(err, http.StatusText(http.StatusUnauthorized), codeUnauthorized)}
 }
 
 // Unauthorized implements middleware's unauthorized interface.
 // The return value of this function is irrelevant.
 func (*ErrUnauthorized) Unauthorized() bool { return true }
 
-// Msg implements middleware's message interface.
-func (e *ErrUnauthorized) Msg() string { return e.message }
+// Cause implements the pkg/errors interface.
+func (e *ErrUnauthorized) Cause() error { return e.cause() }
 
 // WithMessage returns an unauthorized error after setting a custom message.
 func (e *ErrUnauthorized) WithMessage(message string) *ErrUnauthorized {
@@ -45,45 +40,29 @@ func (e *ErrUnauthorized) StatusOnly() *ErrUnauthorized {
 	return e
 }
 
-// Cause implements the pkg/errors interface.
-func (e *ErrUnauthorized) Cause() error {
-	return e.error
+// WithCode returns an unauthorized error after setting a custom code.
+func (e *ErrUnauthorized) WithCode(code string) *ErrUnauthorized {
+	e.code = code
+	return e
 }
 
-// Format implements the fmt.Formatter.
-// With this method, it's possible to have the stack trace.
-// The stack trace is provided by the pkg/errors.
-func (e *ErrUnauthorized) Format(st fmt.State, verb rune) {
-	if _, ok := e.error.(fmt.Formatter); ok {
-		e.error.(fmt.Formatter).Format(st, verb)
-		return
-	}
-
-	switch verb {
-	case 'v':
-		if st.Flag('+') {
-			for _, f := range e.StackTrace() {
-				f.Format(st, verb)
-			}
-			return
-		}
-		fallthrough
-	case 's':
-		fmt.Fprintf(st, "%q", e.Error())
-	case 'q':
-		_, _ = io.WriteString(st, e.Error())
-	}
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrUnauthorized) WithMessageKey(key string, params map[string]interface{}) *ErrUnauthorized {
+	e.key = key
+	e.params = params
+	return e
 }
 
-// StackTrace implements the pkg/errors stack trace interface.
-// With this method, it's possible to have the stack trace.
-func (e *ErrUnauthorized) StackTrace() errors.StackTrace {
-	type stackTracer interface {
-		StackTrace() errors.StackTrace
-	}
-	if _, ok := e.error.(stackTracer); ok {
-		return e.error.(stackTracer).StackTrace()
-	}
+// WithDetail returns an unauthorized error after adding a detail.
+func (e *ErrUnauthorized) WithDetail(key string, value interface{}) *ErrUnauthorized {
+	e.setDetail(key, value)
+	return e
+}
 
-	return nil
+// Is reports whether target is an unauthorized error with the same code.
+// A target with the default code matches any unauthorized error.
+func (e *ErrUnauthorized) Is(target error) bool {
+	t, ok := target.(*ErrUnauthorized)
+	return ok && e.is(&t.Base, codeUnauthorized)
 }
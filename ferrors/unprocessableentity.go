@@ -0,0 +1,71 @@
+package ferrors
+
+import (
+	"errors"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const codeUnprocessableEntity = "unprocessable_entity"
+
+type ErrUnprocessableEntity struct {
+	Base
+	fields map[string]error
+}
+
+func NewUnprocessableEntity(err error) *ErrUnprocessableEntity {
+	var fields map[string]error
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		fields = errs
+	}
+	return &ErrUnprocessableEntity{
+		Base:   newBase(err, http.StatusText(http.StatusUnprocessableEntity), codeUnprocessableEntity),
+		fields: fields,
+	}
+}
+
+func (*ErrUnprocessableEntity) UnprocessableEntity() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrUnprocessableEntity) Cause() error { return e.cause() }
+
+func (e *ErrUnprocessableEntity) GetFields() map[string]error {
+	return e.fields
+}
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrUnprocessableEntity) WithMessage(message string) *ErrUnprocessableEntity {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrUnprocessableEntity) WithCode(code string) *ErrUnprocessableEntity {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrUnprocessableEntity) WithMessageKey(key string, params map[string]interface{}) *ErrUnprocessableEntity {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrUnprocessableEntity) WithDetail(key string, value interface{}) *ErrUnprocessableEntity {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is an unprocessable entity error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrUnprocessableEntity) Is(target error) bool {
+	t, ok := target.(*ErrUnprocessableEntity)
+	return ok && e.is(&t.Base, codeUnprocessableEntity)
+}
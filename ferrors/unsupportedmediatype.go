@@ -0,0 +1,55 @@
+package ferrors
+
+import "net/http"
+
+const codeUnsupportedMediaType = "unsupported_media_type"
+
+type ErrUnsupportedMediaType struct {
+	Base
+}
+
+func NewUnsupportedMediaType(err error) *ErrUnsupportedMediaType {
+	return &ErrUnsupportedMediaType{
+		Base: newBase(err, http.StatusText(http.StatusUnsupportedMediaType), codeUnsupportedMediaType),
+	}
+}
+
+func (*ErrUnsupportedMediaType) UnsupportedMediaType() bool {
+	return true
+}
+
+// Cause implements the pkg/errors interface.
+func (e *ErrUnsupportedMediaType) Cause() error { return e.cause() }
+
+// WithMessage returns the error after setting a custom message.
+func (e *ErrUnsupportedMediaType) WithMessage(message string) *ErrUnsupportedMediaType {
+	e.message = message
+	return e
+}
+
+// WithCode returns the error after setting a custom code.
+func (e *ErrUnsupportedMediaType) WithCode(code string) *ErrUnsupportedMediaType {
+	e.code = code
+	return e
+}
+
+// WithMessageKey returns the error after setting the catalog key of the message,
+// and its params, used to translate the message.
+func (e *ErrUnsupportedMediaType) WithMessageKey(key string, params map[string]interface{}) *ErrUnsupportedMediaType {
+	e.key = key
+	e.params = params
+	return e
+}
+
+// WithDetail returns the error after adding a detail.
+func (e *ErrUnsupportedMediaType) WithDetail(key string, value interface{}) *ErrUnsupportedMediaType {
+	e.setDetail(key, value)
+	return e
+}
+
+// Is reports whether target is an unsupported media type error with the same code.
+// A target with the default code matches any error of its kind.
+func (e *ErrUnsupportedMediaType) Is(target error) bool {
+	t, ok := target.(*ErrUnsupportedMediaType)
+	return ok && e.is(&t.Base, codeUnsupportedMediaType)
+}
@@ -0,0 +1,116 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/felipeflores/utils/internal/errkind"
+)
+
+// CodeMapper returns the gRPC code of an error of the chain,
+// or false when it does not know the error.
+type CodeMapper func(err error) (codes.Code, bool)
+
+// grpcstatus is implemented by the errors that carry their own gRPC status.
+type grpcstatus interface {
+	GRPCStatus() *status.Status
+}
+
+// isStatus reports whether err carries its own gRPC status.
+func isStatus(err error) bool {
+	_, ok := err.(grpcstatus)
+	return ok
+}
+
+// kindCodes is the gRPC code of each ferrors kind.
+var kindCodes = map[errkind.Kind]codes.Code{
+	errkind.BadRequest:           codes.InvalidArgument,
+	errkind.UnprocessableEntity:  codes.InvalidArgument,
+	errkind.NotAcceptable:        codes.InvalidArgument,
+	errkind.UnsupportedMediaType: codes.InvalidArgument,
+	errkind.NotFound:             codes.NotFound,
+	errkind.Gone:                 codes.NotFound,
+	errkind.Unauthorized:         codes.Unauthenticated,
+	errkind.Forbidden:            codes.PermissionDenied,
+	errkind.Conflict:             codes.AlreadyExists,
+	errkind.PreconditionFailed:   codes.FailedPrecondition,
+	errkind.TooManyRequests:      codes.ResourceExhausted,
+	errkind.PayloadTooLarge:      codes.ResourceExhausted,
+	errkind.RequestTimeout:       codes.DeadlineExceeded,
+	errkind.GatewayTimeout:       codes.DeadlineExceeded,
+	errkind.BadGateway:           codes.Unavailable,
+	errkind.ServiceUnavailable:   codes.Unavailable,
+	errkind.InternalServer:       codes.Internal,
+}
+
+// resolveCode walks the chain of err and returns the code of the innermost
+// error known by the mappers or by the ferrors kinds, and the error itself.
+// Wrapped statuses and context errors only count when no error of the chain
+// is known, so a ferrors kind wrapping a downstream status keeps its own code.
+// Errors not known at all are reported as Unknown, along with err.
+func resolveCode(err error, mappers []CodeMapper) (codes.Code, error) {
+	errs := errkind.Chain(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		if e, ok := errs[i].(errkind.Multier); ok {
+			return multiCode(e, mappers), errs[i]
+		}
+		for _, mapper := range mappers {
+			if code, ok := mapper(errs[i]); ok {
+				return code, errs[i]
+			}
+		}
+		if code, ok := kindCodes[errkind.Of(errs[i])]; ok {
+			return code, errs[i]
+		}
+	}
+	for i := len(errs) - 1; i >= 0; i-- {
+		if code, ok := fallbackCode(errs[i]); ok {
+			return code, errs[i]
+		}
+	}
+	return codes.Unknown, err
+}
+
+// multiCode returns the code of the items of e when they all have the same,
+// or the code of the most severe of them otherwise, servers errors first.
+func multiCode(e errkind.Multier, mappers []CodeMapper) codes.Code {
+	code := codes.OK
+	for _, item := range e.Items() {
+		c, _ := resolveCode(item.Err, mappers)
+		if code == codes.OK || serverCode(c) && !serverCode(code) {
+			code = c
+		}
+	}
+	if code == codes.OK {
+		return codes.Unknown
+	}
+	return code
+}
+
+// fallbackCode returns the code of a status or a context error.
+func fallbackCode(err error) (codes.Code, bool) {
+	if _, ok := err.(grpcstatus); ok {
+		return status.Code(err), true
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, true
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, true
+	default:
+		return 0, false
+	}
+}
+
+// serverCode reports whether code is the fault of the server, like a 5xx status.
+func serverCode(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded, codes.DataLoss, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,180 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/felipeflores/utils/internal/errkind"
+	"github.com/felipeflores/utils/log"
+)
+
+// Middleware converts the errors returned by gRPC handlers into statuses,
+// as httpmiddleware does for HTTP handlers.
+type Middleware struct {
+	mappers []CodeMapper
+	safe    bool
+	logger  log.Logger
+	domain  string
+}
+
+func New(opts ...Option) *Middleware {
+	m := &Middleware{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// UnaryServerInterceptor converts the errors of unary handlers into statuses.
+func (m *Middleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, m.Status(ctx, err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor converts the errors of stream handlers into statuses.
+func (m *Middleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return m.Status(ss.Context(), err).Err()
+		}
+		return nil
+	}
+}
+
+// Status converts err into a status. The code, the ErrorInfo, BadRequest and
+// RetryInfo details come from the innermost known error of the chain.
+// Errors that already are statuses are returned as they are, except server
+// statuses with safe errors, whose message is hidden like any other error.
+func (m *Middleware) Status(ctx context.Context, err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	code, kind := resolveCode(err, m.mappers)
+	if e, ok := err.(grpcstatus); ok && isStatus(kind) && !(m.safe && serverCode(code)) {
+		return e.GRPCStatus()
+	}
+
+	message := err.Error()
+	var details []protoiface.MessageV1
+	if m.safe {
+		message = m.hideDetails(ctx, code, err, kind, &details)
+	}
+	hidden := m.safe && serverCode(code)
+
+	if e, ok := kind.(errkind.Coder); ok {
+		info := &errdetails.ErrorInfo{Reason: e.Code(), Domain: m.domain}
+		if e, ok := kind.(errkind.Detailer); ok && !hidden && len(e.Details()) > 0 {
+			info.Metadata = make(map[string]string, len(e.Details()))
+			for key, value := range e.Details() {
+				info.Metadata[key] = fmt.Sprint(value)
+			}
+		}
+		details = append(details, info)
+	}
+	if violations := m.fieldViolations(ctx, kind); len(violations) > 0 && !serverCode(code) {
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if e, ok := kind.(errkind.RetryAfterer); ok && e.RetryAfter() > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter())})
+	}
+
+	st := status.New(code, message)
+	if len(details) == 0 {
+		return st
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// fieldViolations lists the field errors of kind, sorted by field, or the
+// item errors of a multi error, whose fields are prefixed by the item path.
+func (m *Middleware) fieldViolations(ctx context.Context, kind error) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if e, ok := kind.(errkind.Fielder); ok {
+		for field, err := range e.GetFields() {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: err.Error(),
+			})
+		}
+	}
+	if e, ok := kind.(errkind.Multier); ok {
+		for _, item := range e.Items() {
+			st := m.Status(ctx, item.Err)
+			fields := m.fieldViolations(ctx, itemKind(item.Err, m.mappers))
+			if len(fields) == 0 {
+				violations = append(violations, &errdetails.BadRequest_FieldViolation{
+					Field:       item.Path,
+					Description: st.Message(),
+				})
+			}
+			for _, f := range fields {
+				f.Field = item.Path + "." + f.Field
+				violations = append(violations, f)
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	return violations
+}
+
+func itemKind(err error, mappers []CodeMapper) error {
+	_, kind := resolveCode(err, mappers)
+	return kind
+}
+
+// hideDetails returns a generic message and adds an error ID to the details of
+// server errors, logging the full error, or the user facing message otherwise.
+func (m *Middleware) hideDetails(ctx context.Context, code codes.Code, err, kind error, details *[]protoiface.MessageV1) string {
+	method, _ := grpc.Method(ctx)
+	if !serverCode(code) {
+		if m.logger != nil {
+			m.logger.Warn(err.Error(),
+				zap.String("code", code.String()),
+				zap.String("method", method),
+			)
+		}
+		if e, ok := kind.(errkind.Messager); ok && e.Msg() != "" {
+			return e.Msg()
+		}
+		return code.String()
+	}
+
+	errorID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			errorID = v[0]
+		}
+	}
+	if errorID == "" {
+		errorID = uuid.New().String()
+	}
+	*details = append(*details, &errdetails.RequestInfo{RequestId: errorID})
+	if m.logger != nil {
+		m.logger.Error(err.Error(),
+			zap.String("error_id", errorID),
+			zap.String("code", code.String()),
+			zap.String("method", method),
+			zap.String("stack", errkind.StackTrace(err)),
+		)
+	}
+	return code.String()
+}
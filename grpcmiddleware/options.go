@@ -0,0 +1,33 @@
+package grpcmiddleware
+
+import "github.com/felipeflores/utils/log"
+
+// Option configures a Middleware.
+type Option func(*Middleware)
+
+// WithCodeMapper registers a mapper consulted, before the ferrors kinds,
+// for every error of the chain. Mappers are called in the order they are registered.
+func WithCodeMapper(mapper CodeMapper) Option {
+	return func(m *Middleware) {
+		m.mappers = append(m.mappers, mapper)
+	}
+}
+
+// WithSafeErrors hides internal details from the returned statuses:
+// server statuses, such as Internal, Unknown and Unavailable, get a generic
+// message and an error ID, sent as RequestInfo, while the full error is logged
+// with that ID; other statuses get the user facing message of the ferrors kind.
+// The error ID is the x-request-id metadata of the call, or a new UUID.
+func WithSafeErrors(logger log.Logger) Option {
+	return func(m *Middleware) {
+		m.safe = true
+		m.logger = logger
+	}
+}
+
+// WithDomain sets the domain of the ErrorInfo details, such as the service name.
+func WithDomain(domain string) Option {
+	return func(m *Middleware) {
+		m.domain = domain
+	}
+}
@@ -0,0 +1,95 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/felipeflores/utils/ferrors"
+	"github.com/felipeflores/utils/internal/errkind"
+)
+
+// ToStatus converts err into a status with the default Middleware.
+func ToStatus(err error) *status.Status {
+	return New().Status(context.Background(), err)
+}
+
+// FromError converts an error returned by a gRPC call into the matching ferrors kind.
+// It returns nil for a nil error, and err itself when it is not a status.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// FromStatus converts a status into the matching ferrors kind, wrapping the
+// status error. The ErrorInfo reason is kept as the code, BadRequest field
+// violations as validation.Errors and RetryInfo as the retry after duration.
+// It returns nil for an OK status.
+func FromStatus(st *status.Status) error {
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	var (
+		code   string
+		fields validation.Errors
+		wait   time.Duration
+	)
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			code = d.GetReason()
+		case *errdetails.BadRequest:
+			fields = validation.Errors{}
+			for _, v := range d.GetFieldViolations() {
+				fields[v.GetField()] = errors.New(v.GetDescription())
+			}
+		case *errdetails.RetryInfo:
+			wait = d.GetRetryDelay().AsDuration()
+		}
+	}
+
+	cause := st.Err()
+	withFields := func() error {
+		if len(fields) == 0 {
+			return cause
+		}
+		return fields
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return errkind.WithCode(ferrors.NewBadRequest(withFields()).WithMessage(st.Message()), code)
+	case codes.NotFound:
+		return errkind.WithCode(ferrors.NewNotFound(cause).WithMessage(st.Message()), code)
+	case codes.Unauthenticated:
+		return errkind.WithCode(ferrors.NewUnauthorized(cause).WithMessage(st.Message()), code)
+	case codes.PermissionDenied:
+		return errkind.WithCode(ferrors.NewForbidden(cause).WithMessage(st.Message()), code)
+	case codes.AlreadyExists, codes.Aborted:
+		return errkind.WithCode(ferrors.NewConflict(withFields()).WithMessage(st.Message()), code)
+	case codes.FailedPrecondition:
+		return errkind.WithCode(ferrors.NewPreconditionFailed(cause).WithMessage(st.Message()), code)
+	case codes.ResourceExhausted:
+		return errkind.WithCode(ferrors.NewTooManyRequests(cause).WithMessage(st.Message()).WithRetryAfter(wait), code)
+	case codes.DeadlineExceeded:
+		return errkind.WithCode(ferrors.NewGatewayTimeout(cause).WithMessage(st.Message()), code)
+	case codes.Unavailable:
+		return errkind.WithCode(ferrors.NewServiceUnavailable(cause).WithMessage(st.Message()).WithRetryAfter(wait), code)
+	case codes.Canceled:
+		return errkind.WithCode(ferrors.NewRequestTimeout(cause).WithMessage(st.Message()), code)
+	default:
+		return errkind.WithCode(ferrors.NewInternalServer(cause).WithMessage(st.Message()), code)
+	}
+}
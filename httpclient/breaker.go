@@ -0,0 +1,251 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit of an upstream host.
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures the circuit breaker kept for each upstream host.
+type BreakerConfig struct {
+	// ConsecutiveFailures opens the circuit after that many failures in a row.
+	// Zero disables this threshold.
+	ConsecutiveFailures int
+	// FailureRatio opens the circuit when failures/requests reaches it inside
+	// Window, once at least MinRequests were made. Zero disables this threshold.
+	FailureRatio float64
+	MinRequests  int
+	Window       time.Duration
+	// CoolDown is how long the circuit stays open before letting probes through.
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of successful probes needed to close the circuit.
+	HalfOpenRequests int
+	// IsFailure decides whether a call counts as a failure.
+	// By default transport errors and 5xx responses are failures.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called every time the circuit of a host changes state.
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// DefaultBreakerConfig returns a config that opens after 5 consecutive failures
+// or 50% of failures over 20 requests in 10 seconds, cooling down for 30 seconds.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		ConsecutiveFailures: 5,
+		FailureRatio:        0.5,
+		MinRequests:         20,
+		Window:              10 * time.Second,
+		CoolDown:            30 * time.Second,
+		HalfOpenRequests:    1,
+	}
+}
+
+// ErrCircuitOpen is returned without calling the upstream while its circuit is open.
+type ErrCircuitOpen struct {
+	Host string
+	// RetryAfter is the remaining cool down time.
+	RetryAfter time.Duration
+}
+
+func (e *ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("circuit breaker is open for host %s", e.Host)
+}
+
+// CircuitOpen can be used to identify the error without a type assertion.
+func (*ErrCircuitOpen) CircuitOpen() bool { return true }
+
+type circuitBreaker struct {
+	config BreakerConfig
+
+	mu    sync.Mutex
+	hosts map[string]*hostCircuit
+}
+
+type hostCircuit struct {
+	state       BreakerState
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	probes      int
+	successes   int
+	// generation changes on every state change, so that calls allowed
+	// in a previous state are not accounted in the current one.
+	generation uint64
+}
+
+// breakerTicket is the state of the circuit when a call was allowed.
+type breakerTicket struct {
+	state      BreakerState
+	generation uint64
+}
+
+type transition struct {
+	from, to BreakerState
+}
+
+func newCircuitBreaker(c BreakerConfig) *circuitBreaker {
+	if c.HalfOpenRequests < 1 {
+		c.HalfOpenRequests = 1
+	}
+	if c.IsFailure == nil {
+		c.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= http.StatusInternalServerError
+		}
+	}
+	return &circuitBreaker{
+		config: c,
+		hosts:  make(map[string]*hostCircuit),
+	}
+}
+
+// allow returns ErrCircuitOpen when the call must not reach the host,
+// or the ticket to record the outcome of the call with.
+func (cb *circuitBreaker) allow(host string) (breakerTicket, error) {
+	cb.mu.Lock()
+	hc := cb.host(host)
+	now := time.Now()
+
+	var t *transition
+	if hc.state == StateOpen {
+		remaining := cb.config.CoolDown - now.Sub(hc.openedAt)
+		if remaining > 0 {
+			cb.mu.Unlock()
+			return breakerTicket{}, &ErrCircuitOpen{Host: host, RetryAfter: remaining}
+		}
+		t = cb.setState(hc, StateHalfOpen, now)
+	}
+
+	if hc.state == StateHalfOpen {
+		if hc.probes >= cb.config.HalfOpenRequests {
+			cb.mu.Unlock()
+			cb.notify(host, t)
+			return breakerTicket{}, &ErrCircuitOpen{Host: host}
+		}
+		hc.probes++
+	}
+	ticket := breakerTicket{state: hc.state, generation: hc.generation}
+	cb.mu.Unlock()
+
+	cb.notify(host, t)
+	return ticket, nil
+}
+
+// record accounts the outcome of a call previously allowed with ticket.
+// Calls canceled by the caller, or allowed before the last state change, are not counted.
+func (cb *circuitBreaker) record(host string, ticket breakerTicket, req *http.Request, resp *http.Response, err error) {
+	canceled := req.Context().Err() != nil
+	failure := !canceled && cb.config.IsFailure(resp, err)
+
+	cb.mu.Lock()
+	hc := cb.host(host)
+	now := time.Now()
+	if ticket.generation != hc.generation || ticket.state != hc.state {
+		cb.mu.Unlock()
+		return
+	}
+
+	var t *transition
+	switch hc.state {
+	case StateHalfOpen:
+		hc.probes--
+		switch {
+		case canceled:
+		case failure:
+			t = cb.setState(hc, StateOpen, now)
+		default:
+			hc.successes++
+			if hc.successes >= cb.config.HalfOpenRequests {
+				t = cb.setState(hc, StateClosed, now)
+			}
+		}
+	case StateClosed:
+		if canceled {
+			break
+		}
+		if cb.config.Window > 0 && now.Sub(hc.windowStart) > cb.config.Window {
+			hc.windowStart, hc.requests, hc.failures = now, 0, 0
+		}
+		hc.requests++
+		if failure {
+			hc.failures++
+			hc.consecutive++
+		} else {
+			hc.consecutive = 0
+		}
+		if cb.tripped(hc) {
+			t = cb.setState(hc, StateOpen, now)
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify(host, t)
+}
+
+func (cb *circuitBreaker) state(host string) BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.host(host).state
+}
+
+func (cb *circuitBreaker) tripped(hc *hostCircuit) bool {
+	if cb.config.ConsecutiveFailures > 0 && hc.consecutive >= cb.config.ConsecutiveFailures {
+		return true
+	}
+	if cb.config.FailureRatio > 0 && hc.requests >= cb.config.MinRequests {
+		return float64(hc.failures)/float64(hc.requests) >= cb.config.FailureRatio
+	}
+	return false
+}
+
+func (cb *circuitBreaker) host(host string) *hostCircuit {
+	hc, ok := cb.hosts[host]
+	if !ok {
+		hc = &hostCircuit{windowStart: time.Now()}
+		cb.hosts[host] = hc
+	}
+	return hc
+}
+
+func (cb *circuitBreaker) setState(hc *hostCircuit, state BreakerState, now time.Time) *transition {
+	t := &transition{from: hc.state, to: state}
+	hc.state = state
+	hc.generation++
+	hc.probes, hc.successes = 0, 0
+	hc.requests, hc.failures, hc.consecutive = 0, 0, 0
+	hc.windowStart = now
+	if state == StateOpen {
+		hc.openedAt = now
+	}
+	return t
+}
+
+func (cb *circuitBreaker) notify(host string, t *transition) {
+	if t != nil && cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(host, t.from, t.to)
+	}
+}
@@ -0,0 +1,292 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type breakerOp int
+
+const (
+	// opAllow asks for a new call, whose ticket is kept in order.
+	opAllow breakerOp = iota
+	// opSuccess, opFailure and opCancel record the outcome of the call of ticket.
+	opSuccess
+	opFailure
+	opCancel
+)
+
+type breakerStep struct {
+	op     breakerOp
+	ticket int
+	// rejected is whether opAllow is expected to return ErrCircuitOpen.
+	rejected bool
+	// state is the expected state after the step.
+	state BreakerState
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	const host = "upstream"
+	consecutive := BreakerConfig{ConsecutiveFailures: 2, HalfOpenRequests: 1}
+	coolingDown := consecutive
+	coolingDown.CoolDown = time.Hour
+	twoProbes := consecutive
+	twoProbes.HalfOpenRequests = 2
+
+	tests := []struct {
+		name   string
+		config BreakerConfig
+		steps  []breakerStep
+		// transitions is the expected sequence of OnStateChange calls.
+		transitions []transition
+	}{
+		{
+			name:   "consecutive failures open the circuit",
+			config: coolingDown,
+			steps: []breakerStep{
+				{op: opAllow, state: StateClosed},
+				{op: opFailure, ticket: 0, state: StateClosed},
+				{op: opAllow, state: StateClosed},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, rejected: true, state: StateOpen},
+			},
+			transitions: []transition{{StateClosed, StateOpen}},
+		},
+		{
+			name:   "a success resets the consecutive failures",
+			config: coolingDown,
+			steps: []breakerStep{
+				{op: opAllow, state: StateClosed},
+				{op: opFailure, ticket: 0, state: StateClosed},
+				{op: opAllow, state: StateClosed},
+				{op: opSuccess, ticket: 1, state: StateClosed},
+				{op: opAllow, state: StateClosed},
+				{op: opFailure, ticket: 2, state: StateClosed},
+			},
+		},
+		{
+			name:   "canceled calls are not failures",
+			config: coolingDown,
+			steps: []breakerStep{
+				{op: opAllow, state: StateClosed},
+				{op: opCancel, ticket: 0, state: StateClosed},
+				{op: opAllow, state: StateClosed},
+				{op: opFailure, ticket: 1, state: StateClosed},
+			},
+		},
+		{
+			name: "failure ratio opens the circuit",
+			config: BreakerConfig{
+				FailureRatio: 0.5,
+				MinRequests:  4,
+				Window:       time.Hour,
+				CoolDown:     time.Hour,
+			},
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow}, {op: opAllow}, {op: opAllow},
+				{op: opSuccess, ticket: 0, state: StateClosed},
+				{op: opFailure, ticket: 1, state: StateClosed},
+				{op: opSuccess, ticket: 2, state: StateClosed},
+				{op: opFailure, ticket: 3, state: StateOpen},
+			},
+			transitions: []transition{{StateClosed, StateOpen}},
+		},
+		{
+			name:   "a successful probe closes the circuit",
+			config: consecutive,
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow},
+				{op: opFailure, ticket: 0, state: StateClosed},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opAllow, rejected: true, state: StateHalfOpen},
+				{op: opSuccess, ticket: 2, state: StateClosed},
+				{op: opAllow, state: StateClosed},
+			},
+			transitions: []transition{
+				{StateClosed, StateOpen},
+				{StateOpen, StateHalfOpen},
+				{StateHalfOpen, StateClosed},
+			},
+		},
+		{
+			name:   "a failed probe opens the circuit again",
+			config: consecutive,
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow},
+				{op: opFailure, ticket: 0},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opFailure, ticket: 2, state: StateOpen},
+			},
+			transitions: []transition{
+				{StateClosed, StateOpen},
+				{StateOpen, StateHalfOpen},
+				{StateHalfOpen, StateOpen},
+			},
+		},
+		{
+			name:   "every probe must succeed to close the circuit",
+			config: twoProbes,
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow},
+				{op: opFailure, ticket: 0},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opAllow, rejected: true, state: StateHalfOpen},
+				{op: opSuccess, ticket: 2, state: StateHalfOpen},
+				{op: opSuccess, ticket: 3, state: StateClosed},
+			},
+			transitions: []transition{
+				{StateClosed, StateOpen},
+				{StateOpen, StateHalfOpen},
+				{StateHalfOpen, StateClosed},
+			},
+		},
+		{
+			name:   "a canceled probe frees its slot",
+			config: consecutive,
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow},
+				{op: opFailure, ticket: 0},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opCancel, ticket: 2, state: StateHalfOpen},
+				{op: opAllow, state: StateHalfOpen},
+			},
+			transitions: []transition{
+				{StateClosed, StateOpen},
+				{StateOpen, StateHalfOpen},
+			},
+		},
+		{
+			name:   "a call allowed while closed is not accounted as a probe",
+			config: consecutive,
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow}, {op: opAllow}, {op: opAllow},
+				{op: opFailure, ticket: 0},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				// The calls of tickets 2 and 3 started before the circuit opened.
+				{op: opSuccess, ticket: 2, state: StateHalfOpen},
+				{op: opAllow, rejected: true, state: StateHalfOpen},
+				{op: opFailure, ticket: 3, state: StateHalfOpen},
+				{op: opAllow, rejected: true, state: StateHalfOpen},
+				{op: opSuccess, ticket: 4, state: StateClosed},
+			},
+			transitions: []transition{
+				{StateClosed, StateOpen},
+				{StateOpen, StateHalfOpen},
+				{StateHalfOpen, StateClosed},
+			},
+		},
+		{
+			name:   "a probe of a previous half open state is ignored",
+			config: twoProbes,
+			steps: []breakerStep{
+				{op: opAllow}, {op: opAllow},
+				{op: opFailure, ticket: 0},
+				{op: opFailure, ticket: 1, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opFailure, ticket: 2, state: StateOpen},
+				{op: opAllow, state: StateHalfOpen},
+				// The probe of ticket 3 belongs to the previous half open state.
+				{op: opSuccess, ticket: 3, state: StateHalfOpen},
+				{op: opSuccess, ticket: 4, state: StateHalfOpen},
+				{op: opAllow, state: StateHalfOpen},
+				{op: opSuccess, ticket: 5, state: StateClosed},
+			},
+			transitions: []transition{
+				{StateClosed, StateOpen},
+				{StateOpen, StateHalfOpen},
+				{StateHalfOpen, StateOpen},
+				{StateOpen, StateHalfOpen},
+				{StateHalfOpen, StateClosed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transitions []transition
+			config := tt.config
+			config.OnStateChange = func(h string, from, to BreakerState) {
+				if h != host {
+					t.Errorf("OnStateChange host = %q, want %q", h, host)
+				}
+				transitions = append(transitions, transition{from, to})
+			}
+			cb := newCircuitBreaker(config)
+
+			var tickets []breakerTicket
+			for i, step := range tt.steps {
+				switch step.op {
+				case opAllow:
+					ticket, err := cb.allow(host)
+					var open *ErrCircuitOpen
+					if rejected := errors.As(err, &open); rejected != step.rejected {
+						t.Fatalf("step %d: allow error = %v, want rejected %v", i, err, step.rejected)
+					}
+					if err == nil {
+						tickets = append(tickets, ticket)
+					}
+				default:
+					recordOutcome(cb, host, tickets[step.ticket], step.op)
+				}
+				if got := cb.state(host); got != step.state {
+					t.Fatalf("step %d: state = %v, want %v", i, got, step.state)
+				}
+			}
+
+			if len(transitions) != len(tt.transitions) {
+				t.Fatalf("transitions = %v, want %v", transitions, tt.transitions)
+			}
+			for i := range transitions {
+				if transitions[i] != tt.transitions[i] {
+					t.Fatalf("transitions = %v, want %v", transitions, tt.transitions)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerRetryAfter(t *testing.T) {
+	cb := newCircuitBreaker(BreakerConfig{ConsecutiveFailures: 1, CoolDown: time.Hour})
+	ticket, err := cb.allow("upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordOutcome(cb, "upstream", ticket, opFailure)
+
+	_, err = cb.allow("upstream")
+	var open *ErrCircuitOpen
+	if !errors.As(err, &open) {
+		t.Fatalf("allow error = %v, want ErrCircuitOpen", err)
+	}
+	if open.RetryAfter <= 0 || open.RetryAfter > time.Hour {
+		t.Errorf("RetryAfter = %v, want the remaining cool down", open.RetryAfter)
+	}
+	if got := cb.state("other"); got != StateClosed {
+		t.Errorf("state of another host = %v, want %v", got, StateClosed)
+	}
+}
+
+func recordOutcome(cb *circuitBreaker, host string, ticket breakerTicket, op breakerOp) {
+	req := httptest.NewRequest(http.MethodGet, "http://"+host, nil)
+	switch op {
+	case opSuccess:
+		cb.record(host, ticket, req, &http.Response{StatusCode: http.StatusOK}, nil)
+	case opFailure:
+		cb.record(host, ticket, req, &http.Response{StatusCode: http.StatusInternalServerError}, nil)
+	case opCancel:
+		ctx, cancel := context.WithCancel(req.Context())
+		cancel()
+		cb.record(host, ticket, req.WithContext(ctx), nil, context.Canceled)
+	}
+}
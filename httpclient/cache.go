@@ -0,0 +1,318 @@
+package httpclient
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response kept by a CacheStore.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Expires is when the response stops being fresh and must be revalidated.
+	Expires time.Time
+	// Vary holds the request header values the response varies on.
+	Vary map[string]string
+}
+
+// CacheStore stores cached responses by key.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, r *CachedResponse)
+	Delete(key string)
+}
+
+// LRUStore is an in-memory CacheStore that evicts the least recently used entries.
+type LRUStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value *CachedResponse
+}
+
+// NewLRUStore creates a store keeping at most capacity responses.
+func NewLRUStore(capacity int) *LRUStore {
+	return &LRUStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get implements CacheStore.
+func (s *LRUStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// Set implements CacheStore.
+func (s *LRUStore) Set(key string, r *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.Value.(*lruEntry).value = r
+		s.order.MoveToFront(e)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, value: r})
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete implements CacheStore.
+func (s *LRUStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		s.order.Remove(e)
+		delete(s.entries, key)
+	}
+}
+
+// cacheTransport is a shared HTTP cache for GET requests, honoring Cache-Control,
+// Expires, ETag and Last-Modified. Since a client may serve many users,
+// private responses are never stored, and the entries are keyed by the
+// credentials of the request, so they are only served to the same user.
+// It sits below the interceptors, so the credentials they set, such as
+// the bearer token of a TokenSource, are part of the key.
+type cacheTransport struct {
+	next  http.RoundTripper
+	store CacheStore
+}
+
+// skipCacheKey marks the context of requests that bypass the cache,
+// such as those of DoStream, whose bodies are not buffered.
+type skipCacheKey struct{}
+
+// RoundTrip implements http.RoundTripper.
+func (c *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Context().Value(skipCacheKey{}) != nil {
+		return c.next.RoundTrip(req)
+	}
+	if _, noStore := cacheControl(req.Header)["no-store"]; noStore {
+		return c.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached, ok := c.store.Get(key)
+	if ok && !cached.matches(req) {
+		cached, ok = nil, false
+	}
+
+	if ok {
+		_, noCache := cacheControl(req.Header)["no-cache"]
+		if !noCache && time.Now().Before(cached.Expires) {
+			return cached.response(req), nil
+		}
+
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		drain(resp)
+		// Entries are shared, so the revalidated one is a copy.
+		updated := *cached
+		updated.Header = cached.Header.Clone()
+		for key, values := range resp.Header {
+			updated.Header[key] = values
+		}
+		// A 304 may omit Cache-Control, so freshness comes from the merged headers.
+		updated.Expires = expires(updated.Header)
+		c.store.Set(key, &updated)
+		return updated.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !cacheable(req, resp.Header) {
+		if resp.StatusCode == http.StatusOK {
+			c.store.Delete(key)
+		}
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	entry := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Expires:    expires(resp.Header),
+		Vary:       make(map[string]string),
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		entry.Vary[name] = req.Header.Get(name)
+	}
+	c.store.Set(key, entry)
+	return resp, nil
+}
+
+func (r *CachedResponse) matches(req *http.Request) bool {
+	for name, value := range r.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// credentialHeaders identify the user of a request.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// cacheKey returns the URL of req, along with a hash of its credentials, if any.
+func cacheKey(req *http.Request) string {
+	key := req.URL.String()
+	h := sha256.New()
+	found := false
+	for _, name := range credentialHeaders {
+		for _, v := range req.Header.Values(name) {
+			found = true
+			h.Write([]byte(name + ": " + v + "\n"))
+		}
+	}
+	if !found {
+		return key
+	}
+	return key + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheable reports whether the response can be stored by a shared cache, that is,
+// it is not marked as no-store or private, it is explicitly shareable when the
+// request has an Authorization header, it does not vary on every header, and it
+// is either fresh for some time or can be revalidated.
+func cacheable(req *http.Request, header http.Header) bool {
+	cc := cacheControl(header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if _, ok := cc["private"]; ok {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" {
+		_, public := cc["public"]
+		_, sMaxAge := cc["s-maxage"]
+		_, mustRevalidate := cc["must-revalidate"]
+		if !public && !sMaxAge && !mustRevalidate {
+			return false
+		}
+	}
+	for _, name := range varyHeaders(header) {
+		if name == "*" {
+			return false
+		}
+	}
+	return time.Now().Before(expires(header)) ||
+		header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+// expires returns when the response stops being fresh.
+func expires(header http.Header) time.Time {
+	now := time.Now()
+	cc := cacheControl(header)
+	if _, ok := cc["no-cache"]; ok {
+		return now
+	}
+	maxAge, ok := cc["s-maxage"]
+	if !ok {
+		maxAge, ok = cc["max-age"]
+	}
+	if ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return now
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if v := header.Get("Expires"); v != "" {
+		t, err := http.ParseTime(v)
+		if err != nil {
+			return now
+		}
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			// Expires is relative to the upstream clock.
+			return now.Add(t.Sub(date))
+		}
+		return t
+	}
+	return now
+}
+
+func cacheControl(header http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, part := range strings.Split(header.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
+
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,238 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/felipeflores/utils/httpclient/model"
+)
+
+// ErrUnsupportedType is returned by a codec that cannot handle the given value.
+var ErrUnsupportedType = errors.New("codec does not support the value type")
+
+// Codec encodes request bodies and decodes response bodies of a media type.
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Codecs is a registry of codecs by media type.
+type Codecs struct {
+	codecs map[string]Codec
+	order  []string
+}
+
+// NewCodecs creates a registry with the given codecs.
+func NewCodecs(codecs ...Codec) *Codecs {
+	c := &Codecs{codecs: make(map[string]Codec)}
+	for _, codec := range codecs {
+		c.Register(codec.ContentType(), codec)
+	}
+	return c
+}
+
+// DefaultCodecs returns a registry with JSON, XML, form, protobuf and plain text codecs.
+func DefaultCodecs() *Codecs {
+	c := NewCodecs(JSONCodec{}, XMLCodec{}, FormCodec{}, ProtobufCodec{}, TextCodec{})
+	c.Register("text/xml", XMLCodec{})
+	c.Register("application/protobuf", ProtobufCodec{})
+	return c
+}
+
+// Register adds codec for mediaType, replacing any previous one.
+func (c *Codecs) Register(mediaType string, codec Codec) {
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := c.codecs[mediaType]; !ok {
+		c.order = append(c.order, mediaType)
+	}
+	c.codecs[mediaType] = codec
+}
+
+// Lookup returns the codec for a Content-Type header value.
+// Structured syntax suffixes, such as application/problem+json, fall back
+// to the codec of the suffix.
+func (c *Codecs) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	if codec, ok := c.codecs[mediaType]; ok {
+		return codec, true
+	}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		codec, ok := c.codecs["application/"+mediaType[i+1:]]
+		return codec, ok
+	}
+	return nil, false
+}
+
+// accept returns an Accept header value preferring the media type of preferred.
+func (c *Codecs) accept(preferred Codec) string {
+	types := []string{preferred.ContentType()}
+	for _, mediaType := range c.order {
+		if mediaType != preferred.ContentType() {
+			types = append(types, mediaType+";q=0.9")
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+// decode unmarshals data with the codec of contentType. Unknown media types,
+// and values not supported by their codec, are decoded as JSON, since many
+// upstreams send JSON with a wrong Content-Type.
+func (c *Codecs) decode(contentType string, data []byte, v interface{}) error {
+	codec, ok := c.Lookup(contentType)
+	if !ok {
+		return JSONCodec{}.Unmarshal(data, v)
+	}
+	err := codec.Unmarshal(data, v)
+	if errors.Is(err, ErrUnsupportedType) {
+		return JSONCodec{}.Unmarshal(data, v)
+	}
+	return err
+}
+
+// JSONCodec encodes application/json.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string                        { return model.ApplicationJSON }
+func (JSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// XMLCodec encodes application/xml.
+type XMLCodec struct{}
+
+func (XMLCodec) ContentType() string                        { return "application/xml" }
+func (XMLCodec) Marshal(v interface{}) ([]byte, error)      { return xml.Marshal(v) }
+func (XMLCodec) Unmarshal(data []byte, v interface{}) error { return xml.Unmarshal(data, v) }
+
+// FormCodec encodes application/x-www-form-urlencoded from and into
+// url.Values, map[string][]string and map[string]string.
+type FormCodec struct{}
+
+func (FormCodec) ContentType() string { return model.FormUrlEncoded }
+
+func (FormCodec) Marshal(v interface{}) ([]byte, error) {
+	switch e := v.(type) {
+	case url.Values:
+		return []byte(e.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(e).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range e {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+}
+
+func (FormCodec) Unmarshal(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch e := v.(type) {
+	case *url.Values:
+		*e = values
+	case *map[string][]string:
+		*e = values
+	case *map[string]string:
+		*e = make(map[string]string, len(values))
+		for key := range values {
+			(*e)[key] = values.Get(key)
+		}
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+	return nil
+}
+
+// ProtobufCodec encodes protocol buffers messages in binary wire format,
+// as application/x-protobuf.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+
+	// v may be a pointer to a nil message pointer, such as the Body of a Response[*pb.Message].
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+	elem := rv.Elem()
+	if elem.IsNil() {
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+	m, ok := elem.Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// TextCodec encodes text/plain from and into strings and byte slices.
+type TextCodec struct{}
+
+func (TextCodec) ContentType() string { return "text/plain" }
+
+func (TextCodec) Marshal(v interface{}) ([]byte, error) {
+	switch e := v.(type) {
+	case string:
+		return []byte(e), nil
+	case []byte:
+		return e, nil
+	case fmt.Stringer:
+		return []byte(e.String()), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+}
+
+func (TextCodec) Unmarshal(data []byte, v interface{}) error {
+	switch e := v.(type) {
+	case *string:
+		*e = string(data)
+	case *[]byte:
+		*e = append((*e)[:0], data...)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedType, v)
+	}
+	return nil
+}
+
+// encoder is implemented by the clients that carry their own codecs.
+type encoder interface {
+	codecs() (*Codecs, Codec)
+}
+
+// codecsOf returns the registry and request codec of s, or the defaults.
+func codecsOf(s Sender) (*Codecs, Codec) {
+	if e, ok := s.(encoder); ok {
+		return e.codecs()
+	}
+	return DefaultCodecs(), JSONCodec{}
+}
@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	acceptEncoding  = "Accept-Encoding"
+	contentEncoding = "Content-Encoding"
+)
+
+// skipCompressionKey marks the context of requests whose body is sent as is.
+type skipCompressionKey struct{}
+
+// SkipRequestCompression returns a copy of ctx whose requests have their body
+// sent as is, even with WithGzipRequests. Interceptors that depend on the exact
+// bytes sent, such as signers, use it, since the compression happens after them.
+func SkipRequestCompression(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCompressionKey{}, true)
+}
+
+// compressionTransport asks for gzip, deflate or br responses and decompresses them,
+// and optionally compresses request bodies with gzip.
+type compressionTransport struct {
+	next         http.RoundTripper
+	gzipRequests bool
+}
+
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A request with its own Accept-Encoding handles the response encoding itself.
+	decompress := req.Header.Get(acceptEncoding) == ""
+	compress := t.gzipRequests && req.Body != nil && req.Body != http.NoBody && req.Header.Get(contentEncoding) == "" &&
+		req.Context().Value(skipCompressionKey{}) == nil
+	if decompress || compress {
+		req = req.Clone(req.Context())
+	}
+	if decompress {
+		req.Header.Set(acceptEncoding, "gzip, deflate, br")
+	}
+	if compress {
+		req.Body = gzipBody(req.Body)
+		req.ContentLength = -1
+		req.GetBody = nil
+		req.Header.Del("Content-Length")
+		req.Header.Set(contentEncoding, "gzip")
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !decompress || !hasBody(req, resp) {
+		return resp, err
+	}
+
+	body, err := decompressBody(resp.Header.Get(contentEncoding), resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if body != resp.Body {
+		resp.Body = body
+		resp.Header.Del(contentEncoding)
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	return resp, nil
+}
+
+// gzipBody compresses body while it is read.
+func gzipBody(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+		zw := gzip.NewWriter(pw)
+		if _, err := io.Copy(zw, body); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(zw.Close())
+	}()
+	return pr
+}
+
+func decompressBody(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return readCloser{zr, body}, nil
+	case "deflate":
+		// deflate should be zlib wrapped, but some servers send raw deflate.
+		br := bufio.NewReader(body)
+		header, err := br.Peek(2)
+		if err != nil {
+			return nil, err
+		}
+		if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, err
+			}
+			return readCloser{zr, body}, nil
+		}
+		return readCloser{flate.NewReader(br), body}, nil
+	case "br":
+		return readCloser{brotli.NewReader(body), body}, nil
+	default:
+		return body, nil
+	}
+}
+
+func hasBody(req *http.Request, resp *http.Response) bool {
+	return req.Method != http.MethodHead &&
+		resp.StatusCode != http.StatusNoContent &&
+		resp.StatusCode != http.StatusNotModified &&
+		resp.ContentLength != 0
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/felipeflores/utils/httpclient/model"
+)
+
+// Sender sends a prepared request through the client policies.
+// Every HttpClient implements it, whatever its type parameter.
+type Sender interface {
+	Send(req *http.Request) (*http.Response, error)
+}
+
+// Response holds the status code, headers and decoded body of a call.
+type Response[R any] struct {
+	StatusCode int
+	Header     http.Header
+	Body       R
+}
+
+// Do sends body, when not nil, encoded by the codec of the Content-Type header
+// or the client content type, and decodes a 2xx response into Res by the codec
+// of the response Content-Type.
+// Responses with status 4xx or 5xx are returned along with the matching ferrors error,
+// other responses are returned without decoding their body.
+func Do[Req any, Res any](ctx context.Context, s Sender, method, path string, headers map[string]string, body *Req) (*Response[Res], error) {
+	codecs, codec := codecsOf(s)
+	for key, value := range headers {
+		if http.CanonicalHeaderKey(key) == model.ContentType {
+			if c, ok := codecs.Lookup(value); ok {
+				codec = c
+			}
+		}
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := codec.Marshal(*body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set(model.ContentType, codec.ContentType())
+	}
+	req.Header.Set(model.Accept, codecs.accept(codec))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.Send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	r := &Response[Res]{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return r, err
+	}
+	if err := responseError(resp, b); err != nil {
+		return r, err
+	}
+	if !isSuccess(resp.StatusCode) || len(b) == 0 {
+		return r, nil
+	}
+
+	if err := codecs.decode(resp.Header.Get(model.ContentType), b, &r.Body); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/felipeflores/utils/ferrors"
+	"github.com/felipeflores/utils/httpmiddleware"
+	"github.com/felipeflores/utils/internal/errkind"
+)
+
+// maxErrorBody caps how much of a non JSON error body is kept in the message.
+const maxErrorBody = 512
+
+// UpstreamError is the cause wrapped by the ferrors returned for responses
+// with status 4xx or 5xx, use errors.As to get it. Field errors of 400, 409 and 422
+// responses are wrapped along with it as validation.Errors, so they are kept by the ferrors kind.
+type UpstreamError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Message    string
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("upstream responded %d: %s", e.StatusCode, e.Message)
+}
+
+// responseError converts a response with status 4xx or 5xx into the matching ferrors kind.
+// It returns nil for any other status.
+func responseError(resp *http.Response, body []byte) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	upstream := &UpstreamError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+
+	errResp := parseErrorResponse(body)
+	if errResp.Message != "" {
+		upstream.Message = errResp.Message
+	} else if text := strings.TrimSpace(string(body)); text != "" {
+		if len(text) > maxErrorBody {
+			text = text[:maxErrorBody]
+		}
+		upstream.Message = text
+	} else {
+		upstream.Message = http.StatusText(resp.StatusCode)
+	}
+
+	code := errResp.Code
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		return errkind.WithCode(ferrors.NewBadRequest(withFields(upstream, errResp.Fields)), code)
+	case http.StatusUnauthorized:
+		return errkind.WithCode(ferrors.NewUnauthorized(upstream), code)
+	case http.StatusForbidden:
+		return errkind.WithCode(ferrors.NewForbidden(upstream), code)
+	case http.StatusNotFound:
+		return errkind.WithCode(ferrors.NewNotFound(upstream), code)
+	case http.StatusNotAcceptable:
+		return errkind.WithCode(ferrors.NewNotAcceptable(upstream), code)
+	case http.StatusRequestTimeout:
+		return errkind.WithCode(ferrors.NewRequestTimeout(upstream), code)
+	case http.StatusConflict:
+		return errkind.WithCode(ferrors.NewConflict(withFields(upstream, errResp.Fields)), code)
+	case http.StatusGone:
+		return errkind.WithCode(ferrors.NewGone(upstream), code)
+	case http.StatusPreconditionFailed:
+		return errkind.WithCode(ferrors.NewPreconditionFailed(upstream), code)
+	case http.StatusRequestEntityTooLarge:
+		return errkind.WithCode(ferrors.NewPayloadTooLarge(upstream), code)
+	case http.StatusUnsupportedMediaType:
+		return errkind.WithCode(ferrors.NewUnsupportedMediaType(upstream), code)
+	case http.StatusUnprocessableEntity:
+		return errkind.WithCode(ferrors.NewUnprocessableEntity(withFields(upstream, errResp.Fields)), code)
+	case http.StatusTooManyRequests:
+		wait, _ := retryAfter(resp)
+		return errkind.WithCode(ferrors.NewTooManyRequests(upstream).WithRetryAfter(wait), code)
+	case http.StatusBadGateway:
+		return errkind.WithCode(ferrors.NewBadGateway(upstream), code)
+	case http.StatusServiceUnavailable:
+		wait, _ := retryAfter(resp)
+		return errkind.WithCode(ferrors.NewServiceUnavailable(upstream).WithRetryAfter(wait), code)
+	case http.StatusGatewayTimeout:
+		return errkind.WithCode(ferrors.NewGatewayTimeout(upstream), code)
+	default:
+		return errkind.WithCode(ferrors.NewInternalServer(upstream), code)
+	}
+}
+
+// parseErrorResponse reads an ErrorResponse, or RFC 7807 problem details, from body.
+func parseErrorResponse(body []byte) httpmiddleware.ErrorResponse {
+	var errResp httpmiddleware.ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Message != "" {
+		return errResp
+	}
+
+	var problem httpmiddleware.Problem
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return errResp
+	}
+	errResp.Message = problem.Detail
+	if errResp.Message == "" {
+		errResp.Message = problem.Title
+	}
+	errResp.Code = problem.Code
+	for _, p := range problem.InvalidParams {
+		errResp.Fields = append(errResp.Fields, httpmiddleware.Field{Name: p.Name, Message: p.Reason})
+	}
+	return errResp
+}
+
+// withFields returns the upstream error along with its field errors, as
+// validation.Errors, so ferrors keep them, or the upstream error when there are none.
+func withFields(upstream *UpstreamError, fields []httpmiddleware.Field) error {
+	if len(fields) == 0 {
+		return upstream
+	}
+	errs := validation.Errors{}
+	for _, f := range fields {
+		errs[f.Name] = errors.New(f.Message)
+	}
+	return &upstreamFields{UpstreamError: upstream, fields: errs}
+}
+
+// upstreamFields is an upstream error with the field errors it carries.
+type upstreamFields struct {
+	*UpstreamError
+	fields validation.Errors
+}
+
+// Unwrap returns the upstream error and its field errors, for errors.As.
+func (e *upstreamFields) Unwrap() []error {
+	return []error{e.UpstreamError, e.fields}
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HedgePolicy configures hedged GET requests: when a request takes longer than
+// Delay, another one is sent, the first successful response is used and the
+// others are canceled.
+type HedgePolicy struct {
+	// Delay is the wait before each hedged request.
+	Delay time.Duration
+	// MaxRequests is the total number of concurrent requests. Defaults to 2.
+	MaxRequests int
+	// AttemptTimeout bounds each request, including reading its body,
+	// independently of the context deadline. Zero means no timeout.
+	AttemptTimeout time.Duration
+}
+
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// hedged sends req following the hedge policy. A response is successful when
+// there is no transport error and its status is below 500.
+func (h *HttpClient[T]) hedged(req *http.Request) (*http.Response, error) {
+	p := h.hedge
+	maxRequests := p.MaxRequests
+	if maxRequests < 2 {
+		maxRequests = 2
+	}
+
+	results := make(chan hedgeResult, maxRequests)
+	var cancels []context.CancelFunc
+	launch := func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if p.AttemptTimeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), p.AttemptTimeout)
+		} else {
+			ctx, cancel = context.WithCancel(req.Context())
+		}
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		r := req.Clone(ctx)
+		go func() {
+			resp, err := h.send(r)
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(p.Delay)
+	defer timer.Stop()
+
+	received := 0
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) < maxRequests {
+				launch()
+				timer.Reset(p.Delay)
+			}
+		case r := <-results:
+			received++
+			pending := len(cancels) - received
+			success := r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+
+			if success || (pending == 0 && len(cancels) == maxRequests) {
+				for i, cancel := range cancels {
+					if i != r.index {
+						cancel()
+					}
+				}
+				go discard(results, pending)
+				if r.err != nil {
+					cancels[r.index]()
+					return nil, r.err
+				}
+				r.resp.Body = cancelOnClose{ReadCloser: r.resp.Body, cancel: cancels[r.index]}
+				return r.resp, nil
+			}
+
+			if r.resp != nil {
+				drain(r.resp)
+			}
+			cancels[r.index]()
+			// A failed request is hedged right away.
+			if len(cancels) < maxRequests {
+				launch()
+				timer.Reset(p.Delay)
+			}
+		}
+	}
+}
+
+// discard closes the responses of the canceled requests.
+func discard(results chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		if r := <-results; r.resp != nil {
+			drain(r.resp)
+		}
+	}
+}
+
+// cancelOnClose releases the request context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -3,40 +3,70 @@ package httpclient
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 
 	"github.com/felipeflores/utils/httpclient/model"
 )
 
 type HttpClient[T any] struct {
-	client *http.Client
+	client  *http.Client
+	retry   *RetryPolicy
+	breaker *circuitBreaker
+	hedge   *HedgePolicy
+
+	codecSet *Codecs
+	reqCodec Codec
 }
 
-func New[T any]() *HttpClient[T] {
-	client := &http.Client{}
-	return &HttpClient[T]{
-		client: client,
+func New[T any](opts ...Option) *HttpClient[T] {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	client := &http.Client{
+		Transport: buildTransport(o),
+		Timeout:   o.timeout,
+	}
+	h := &HttpClient[T]{
+		client:   client,
+		retry:    o.retry,
+		hedge:    o.hedge,
+		codecSet: o.codecs,
+		reqCodec: JSONCodec{},
+	}
+	if h.codecSet == nil {
+		h.codecSet = DefaultCodecs()
+	}
+	if c, ok := h.codecSet.Lookup(o.contentType); ok {
+		h.reqCodec = c
+	}
+	if o.breaker != nil {
+		h.breaker = newCircuitBreaker(*o.breaker)
 	}
+	return h
 }
 
 func (h *HttpClient[T]) PostFormUrlEncoded(path string, formData map[string]string, response *T) error {
+	return h.PostFormUrlEncodedWithContext(context.Background(), path, formData, response)
+}
+
+// PostFormUrlEncodedWithContext is PostFormUrlEncoded bound to ctx.
+func (h *HttpClient[T]) PostFormUrlEncodedWithContext(ctx context.Context, path string, formData map[string]string, response *T) error {
 	data := url.Values{}
 	for key, value := range formData {
 		data.Set(key, value)
 	}
 
-	req, err := http.NewRequest(model.Post, path, bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, model.Post, path, bytes.NewBufferString(data.Encode()))
 	if err != nil {
 		return err
 	}
 	req.Header.Set(model.ContentType, model.FormUrlEncoded)
 
-	resp, err := h.client.Do(req)
+	resp, err := h.do(req)
 	if err != nil {
 		return err
 	}
@@ -46,8 +76,11 @@ func (h *HttpClient[T]) PostFormUrlEncoded(path string, formData map[string]stri
 	if err != nil {
 		return err
 	}
+	if err := responseError(resp, body); err != nil {
+		return err
+	}
 
-	err = json.Unmarshal(body, &response)
+	err = h.codecSet.decode(resp.Header.Get(model.ContentType), body, response)
 	if err != nil {
 		return err
 	}
@@ -55,8 +88,11 @@ func (h *HttpClient[T]) PostFormUrlEncoded(path string, formData map[string]stri
 	return nil
 }
 
+// Post sends body encoded by the client content type. The returned response
+// has its body already closed, use Do to have the response decoded.
+// Responses with status 4xx or 5xx are returned along with the matching ferrors error.
 func (h *HttpClient[T]) Post(ctx context.Context, path string, headers map[string]string, body T) (int, *http.Response, error) {
-	b, err := json.Marshal(body)
+	b, err := h.reqCodec.Marshal(body)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -70,27 +106,23 @@ func (h *HttpClient[T]) Post(ctx context.Context, path string, headers map[strin
 	for key, value := range headers {
 		req.Header.Add(key, value)
 	}
-
-	// Dump the request
-	requestDump, err := httputil.DumpRequestOut(req, true)
-	if err != nil {
-		fmt.Println("Error dumping request:", err)
-		return 0, nil, err
+	if req.Header.Get(model.ContentType) == "" {
+		req.Header.Set(model.ContentType, h.reqCodec.ContentType())
 	}
-	fmt.Printf("Request:\n%s\n", string(requestDump))
 
-	resp, err := h.client.Do(req)
+	resp, err := h.do(req)
 	if err != nil {
 		return 0, nil, err
 	}
 	defer resp.Body.Close()
-	// Dump the response
-	responseDump, err := httputil.DumpResponse(resp, true)
-	if err != nil {
-		fmt.Println("Error dumping response:", err)
-		return 0, nil, err
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, resp, err
+		}
+		return resp.StatusCode, resp, responseError(resp, b)
 	}
-	fmt.Printf("Response:\n%s\n", string(responseDump))
 	return resp.StatusCode, resp, nil
 }
 
@@ -104,7 +136,7 @@ func (h *HttpClient[T]) Get(ctx context.Context, path string, headers map[string
 		req.Header.Add(key, value)
 	}
 
-	resp, err := h.client.Do(req)
+	resp, err := h.do(req)
 	if err != nil {
 		return err
 	}
@@ -114,10 +146,109 @@ func (h *HttpClient[T]) Get(ctx context.Context, path string, headers map[string
 	if err != nil {
 		return err
 	}
+	if err := responseError(resp, body); err != nil {
+		return err
+	}
 
-	err = json.Unmarshal(body, &response)
+	err = h.codecSet.decode(resp.Header.Get(model.ContentType), body, response)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// Put sends body encoded by the client content type and decodes the response into T.
+func (h *HttpClient[T]) Put(ctx context.Context, path string, headers map[string]string, body T) (*Response[T], error) {
+	return Do[T, T](ctx, h, model.Put, path, headers, &body)
+}
+
+// Patch sends body encoded by the client content type and decodes the response into T.
+func (h *HttpClient[T]) Patch(ctx context.Context, path string, headers map[string]string, body T) (*Response[T], error) {
+	return Do[T, T](ctx, h, model.Patch, path, headers, &body)
+}
+
+// Delete decodes the response, if any, into T.
+func (h *HttpClient[T]) Delete(ctx context.Context, path string, headers map[string]string) (*Response[T], error) {
+	return Do[T, T](ctx, h, model.Delete, path, headers, nil)
+}
+
+// Head returns the status code and headers of the resource.
+func (h *HttpClient[T]) Head(ctx context.Context, path string, headers map[string]string) (int, http.Header, error) {
+	r, err := Do[T, struct{}](ctx, h, model.Head, path, headers, nil)
+	if r == nil {
+		return 0, nil, err
+	}
+	return r.StatusCode, r.Header, err
+}
+
+func (h *HttpClient[T]) codecs() (*Codecs, Codec) {
+	return h.codecSet, h.reqCodec
+}
+
+// Send implements Sender.
+func (h *HttpClient[T]) Send(req *http.Request) (*http.Response, error) {
+	return h.do(req)
+}
+
+// do sends the request applying the retry policy, if any.
+func (h *HttpClient[T]) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempts := h.retry.attempts(req)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		var resp *http.Response
+		var err error
+		if h.hedge != nil && req.Method == http.MethodGet {
+			resp, err = h.hedged(req)
+		} else {
+			resp, err = h.send(req)
+		}
+		if attempt >= attempts || !h.retry.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := h.retry.backoff(attempt, resp)
+		if exceedsDeadline(ctx, wait) {
+			return resp, err
+		}
+		if resp != nil {
+			drain(resp)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes a single attempt, guarded by the circuit breaker when enabled.
+func (h *HttpClient[T]) send(req *http.Request) (*http.Response, error) {
+	if h.breaker == nil {
+		return h.client.Do(req)
+	}
+
+	host := req.URL.Host
+	ticket, err := h.breaker.allow(host)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	h.breaker.record(host, ticket, req, resp, err)
+	return resp, err
+}
+
+// BreakerState returns the circuit state of the host.
+// It is always StateClosed when the circuit breaker is not enabled.
+func (h *HttpClient[T]) BreakerState(host string) BreakerState {
+	if h.breaker == nil {
+		return StateClosed
+	}
+	return h.breaker.state(host)
+}
@@ -0,0 +1,203 @@
+package httpclienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TestingT is the part of testing.TB used by Mock.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Mock is an in-memory http.RoundTripper answering requests from expectations.
+// Use it with httpclient.WithTransport; requests never reach the network.
+//
+//	mock := httpclienttest.NewMock()
+//	mock.Expect(http.MethodGet, "/users/1").RespondJSON(http.StatusOK, user)
+//	client := httpclient.New[User](httpclient.WithTransport(mock))
+//	...
+//	mock.AssertExpectations(t)
+type Mock struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// Expectation is an expected call and its response.
+type Expectation struct {
+	method string
+	path   string
+	query  map[string]string
+	header map[string]string
+	body   *string
+	times  int
+	calls  int
+
+	status     int
+	respHeader http.Header
+	respBody   []byte
+	err        error
+}
+
+// NewMock creates a mock with no expectations.
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// Expect adds an expectation for method and URL path, answered with 200 and no body
+// unless a response is set. By default it is expected exactly once.
+func (m *Mock) Expect(method, path string) *Expectation {
+	e := &Expectation{
+		method:     method,
+		path:       path,
+		query:      make(map[string]string),
+		header:     make(map[string]string),
+		times:      1,
+		status:     http.StatusOK,
+		respHeader: make(http.Header),
+	}
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// WithQuery requires a query parameter value.
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.query[key] = value
+	return e
+}
+
+// WithHeader requires a header value.
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.header[key] = value
+	return e
+}
+
+// WithBody requires the exact request body.
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.body = &body
+	return e
+}
+
+// WithJSONBody requires a request body equal, as JSON, to v.
+func (e *Expectation) WithJSONBody(v interface{}) *Expectation {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	body := string(b)
+	e.body = &body
+	return e
+}
+
+// Times sets how many calls are expected. Zero allows any number of calls.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Respond sets the status code and raw body of the response.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status = status
+	e.respBody = []byte(body)
+	return e
+}
+
+// RespondJSON sets the status code and a JSON body of the response.
+func (e *Expectation) RespondJSON(status int, v interface{}) *Expectation {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	e.status = status
+	e.respBody = b
+	e.respHeader.Set("Content-Type", "application/json")
+	return e
+}
+
+// RespondHeader sets a header of the response.
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	e.respHeader.Set(key, value)
+	return e
+}
+
+// RespondError makes the call fail with err, as a transport error.
+func (e *Expectation) RespondError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if (e.times > 0 && e.calls >= e.times) || !e.matches(req, body) {
+			continue
+		}
+		e.calls++
+		if e.err != nil {
+			return nil, e.err
+		}
+		return RecordedResponse{
+			StatusCode: e.status,
+			Header:     e.respHeader,
+			Body:       e.respBody,
+		}.response(req), nil
+	}
+
+	call := fmt.Sprintf("%s %s", req.Method, req.URL.RequestURI())
+	m.unexpected = append(m.unexpected, call)
+	return nil, fmt.Errorf("httpclienttest: unexpected call %s", call)
+}
+
+// AssertExpectations reports unexpected calls and expectations not called
+// the expected number of times.
+func (m *Mock) AssertExpectations(t TestingT) {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, call := range m.unexpected {
+		t.Errorf("unexpected call %s", call)
+	}
+	for _, e := range m.expectations {
+		if e.times > 0 && e.calls != e.times {
+			t.Errorf("expected %s %s to be called %d time(s), got %d", e.method, e.path, e.times, e.calls)
+		}
+	}
+}
+
+func (e *Expectation) matches(req *http.Request, body []byte) bool {
+	if !strings.EqualFold(req.Method, e.method) || req.URL.Path != e.path {
+		return false
+	}
+	query := req.URL.Query()
+	for key, value := range e.query {
+		if query.Get(key) != value {
+			return false
+		}
+	}
+	for key, value := range e.header {
+		if req.Header.Get(key) != value {
+			return false
+		}
+	}
+	if e.body != nil && strings.TrimSpace(string(body)) != strings.TrimSpace(*e.body) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+// Package httpclienttest provides record/replay and mock transports
+// to test code that uses httpclient.HttpClient.
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Mode selects whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette file, never calling the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real upstream and records the interactions.
+	ModeRecord
+)
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the recorded part of a request.
+// The body is written to the cassette as base64, so binary bodies are kept as they are.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the recorded part of a response.
+// The body is written to the cassette as base64, so compressed bodies replay as recorded.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// Matcher reports whether a request matches a recorded one.
+type Matcher func(req RecordedRequest, recorded RecordedRequest) bool
+
+// RecorderConfig configures a Recorder.
+type RecorderConfig struct {
+	// Path is the cassette file.
+	Path string
+	Mode Mode
+	// Transport sends the requests in ModeRecord. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// MatchHeaders lists the headers compared by the default matcher,
+	// which always compares method, URL and body.
+	MatchHeaders []string
+	// Matcher replaces the default matcher.
+	Matcher Matcher
+	// RedactHeaders lists headers that are not written to the cassette.
+	// Defaults to Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	RedactHeaders []string
+}
+
+// Recorder is an http.RoundTripper that records interactions to a cassette file
+// or replays them deterministically. Use it with httpclient.WithTransport.
+type Recorder struct {
+	config RecorderConfig
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder creates a recorder. In ModeReplay the cassette file must exist.
+func NewRecorder(c RecorderConfig) (*Recorder, error) {
+	if c.Transport == nil {
+		c.Transport = http.DefaultTransport
+	}
+	if c.RedactHeaders == nil {
+		c.RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	}
+	if c.Matcher == nil {
+		c.Matcher = defaultMatcher(c.MatchHeaders)
+	}
+
+	r := &Recorder{config: c}
+	if c.Mode == ModeReplay {
+		b, err := ioutil.ReadFile(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.interactions); err != nil {
+			return nil, err
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.config.Mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.config.Path, b, os.FileMode(0644))
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || !r.config.Matcher(recorded, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+		return interaction.Response.response(req), nil
+	}
+	return nil, fmt.Errorf("httpclienttest: no recorded interaction for %s %s", recorded.Method, recorded.URL)
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := r.config.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	recorded.Header = r.redact(recorded.Header)
+	interaction := Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redact(resp.Header),
+			Body:       body,
+		},
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range r.config.RedactHeaders {
+		header.Del(name)
+	}
+	return header
+}
+
+func (r RecordedResponse) response(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return recorded, err
+	}
+	recorded.Body = body
+	return recorded, nil
+}
+
+// readBody reads the request body and puts it back, so the request can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func defaultMatcher(headers []string) Matcher {
+	return func(req RecordedRequest, recorded RecordedRequest) bool {
+		if req.Method != recorded.Method || req.URL != recorded.URL || !bytes.Equal(req.Body, recorded.Body) {
+			return false
+		}
+		for _, name := range headers {
+			if req.Header.Get(name) != recorded.Header.Get(name) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// Interceptor wraps the transport used by the client, so it can change
+// the outgoing requests and observe the responses of every attempt.
+type Interceptor func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// SetHeader returns an interceptor that sets a header on every request.
+func SetHeader(key, value string) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set(key, value)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// buildTransport chains the interceptors, the first one being the outermost,
+// on top of the cache, rate limiter, logging, compression and base transports.
+func buildTransport(o options) http.RoundTripper {
+	base := o.transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if o.tlsConfig != nil {
+		base = withTLSConfig(base, o.tlsConfig)
+	}
+
+	rt := http.RoundTripper(&compressionTransport{next: base, gzipRequests: o.gzipRequests})
+	if o.logging != nil && o.logging.Logger != nil {
+		rt = newLoggingTransport(rt, *o.logging)
+	}
+	if o.rateLimit != nil {
+		rt = newLimiterTransport(rt, *o.rateLimit)
+	}
+	if o.cache != nil {
+		rt = &cacheTransport{next: rt, store: o.cache}
+	}
+	for i := len(o.interceptors) - 1; i >= 0; i-- {
+		rt = o.interceptors[i](rt)
+	}
+	return rt
+}
+
+// withTLSConfig sets the TLS config on a copy of an *http.Transport.
+// Other transports are returned unchanged.
+func withTLSConfig(rt http.RoundTripper, c *tls.Config) http.RoundTripper {
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+	t = t.Clone()
+	t.TLSClientConfig = c
+	return t
+}
@@ -0,0 +1,239 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/felipeflores/utils/httpclient/model"
+	"github.com/felipeflores/utils/log"
+)
+
+// LogLevel sets how much of each exchange is logged.
+type LogLevel int
+
+const (
+	// LogBasic logs method, url, status and latency.
+	LogBasic LogLevel = iota
+	// LogHeaders adds request and response headers.
+	LogHeaders
+	// LogBody adds request and response bodies.
+	LogBody
+)
+
+const redacted = "[REDACTED]"
+
+// LogConfig configures the logging of requests and responses.
+// Successful exchanges are logged as info, 4xx as warn, and 5xx or transport errors as error.
+type LogConfig struct {
+	Logger log.Logger
+	Level  LogLevel
+	// MaxBodySize caps the logged bodies, in bytes. Defaults to 4096.
+	MaxBodySize int
+	// RedactHeaders lists headers whose values are never logged.
+	// Defaults to Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	RedactHeaders []string
+	// RedactFields lists JSON fields, at any depth, form fields and query parameters
+	// whose values are never logged. Defaults to password, client_secret,
+	// access_token and refresh_token.
+	RedactFields []string
+}
+
+type loggingTransport struct {
+	next    http.RoundTripper
+	config  LogConfig
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+func newLoggingTransport(next http.RoundTripper, c LogConfig) *loggingTransport {
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = 4096
+	}
+	if c.RedactHeaders == nil {
+		c.RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	}
+	if c.RedactFields == nil {
+		c.RedactFields = []string{"password", "client_secret", "access_token", "refresh_token"}
+	}
+
+	t := &loggingTransport{
+		next:    next,
+		config:  c,
+		headers: make(map[string]bool),
+		fields:  make(map[string]bool),
+	}
+	for _, h := range c.RedactHeaders {
+		t.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, f := range c.RedactFields {
+		t.fields[strings.ToLower(f)] = true
+	}
+	return t
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", t.redactURL(req.URL)),
+	}
+	if t.config.Level >= LogHeaders {
+		fields = append(fields, zap.Any("request_headers", t.redactHeaders(req.Header)))
+	}
+	if t.config.Level >= LogBody && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := ioutil.ReadAll(io.LimitReader(body, int64(t.config.MaxBodySize)+1))
+			body.Close()
+			fields = append(fields, zap.String("request_body", t.redactBody(req.Header, b)))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	fields = append(fields, zap.Duration("latency", time.Since(start)))
+
+	if err != nil {
+		t.config.Logger.Error("http client request failed", append(fields, zap.Error(err))...)
+		return resp, err
+	}
+
+	fields = append(fields, zap.Int("status", resp.StatusCode))
+	if t.config.Level >= LogHeaders {
+		fields = append(fields, zap.Any("response_headers", t.redactHeaders(resp.Header)))
+	}
+	if t.config.Level >= LogBody {
+		// Only a prefix is read, the caller still gets the whole body.
+		prefix, _ := ioutil.ReadAll(io.LimitReader(resp.Body, int64(t.config.MaxBodySize)+1))
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+		fields = append(fields, zap.String("response_body", t.redactBody(resp.Header, prefix)))
+	}
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.config.Logger.Error("http client request", fields...)
+	case resp.StatusCode >= http.StatusBadRequest:
+		t.config.Logger.Warn("http client request", fields...)
+	default:
+		t.config.Logger.Info("http client request", fields...)
+	}
+	return resp, nil
+}
+
+func (t *loggingTransport) redactHeaders(header http.Header) map[string]string {
+	m := make(map[string]string, len(header))
+	for key, values := range header {
+		if t.headers[http.CanonicalHeaderKey(key)] {
+			m[key] = redacted
+			continue
+		}
+		m[key] = strings.Join(values, ", ")
+	}
+	return m
+}
+
+// redactBody returns the body with the configured JSON or form fields redacted,
+// truncated to MaxBodySize.
+func (t *loggingTransport) redactBody(header http.Header, b []byte) string {
+	contentType := header.Get(model.ContentType)
+	switch {
+	case len(t.fields) == 0:
+	case strings.Contains(contentType, "json"):
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err == nil {
+			if r, err := json.Marshal(t.redactValue(v)); err == nil {
+				b = r
+			}
+		} else if len(b) > t.config.MaxBodySize {
+			// A truncated JSON body cannot be parsed, so it is not logged at all.
+			return redacted
+		}
+	case strings.HasPrefix(strings.ToLower(contentType), model.FormUrlEncoded):
+		return t.redactForm(b)
+	}
+
+	if len(b) > t.config.MaxBodySize {
+		return string(b[:t.config.MaxBodySize]) + "...(truncated)"
+	}
+	return string(b)
+}
+
+// redactForm returns the form body with the configured fields redacted.
+// The last pair of a truncated body may be cut in its name, so it is left out.
+func (t *loggingTransport) redactForm(b []byte) string {
+	truncated := len(b) > t.config.MaxBodySize
+	if truncated {
+		b = b[:t.config.MaxBodySize]
+		if i := bytes.LastIndexByte(b, '&'); i >= 0 {
+			b = b[:i]
+		} else {
+			b = nil
+		}
+	}
+
+	form := t.redactPairs(string(b))
+	if truncated {
+		return form + "...(truncated)"
+	}
+	return form
+}
+
+// redactURL returns the URL with its password and the configured query parameters redacted.
+func (t *loggingTransport) redactURL(u *url.URL) string {
+	if u.RawQuery == "" || len(t.fields) == 0 {
+		return u.Redacted()
+	}
+	redactedURL := *u
+	redactedURL.RawQuery = t.redactPairs(u.RawQuery)
+	return redactedURL.Redacted()
+}
+
+// redactPairs returns the url encoded pairs of a form or a query,
+// with the values of the configured fields redacted.
+func (t *loggingTransport) redactPairs(s string) string {
+	var pairs []string
+	for _, pair := range strings.Split(s, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			return redacted
+		}
+		if t.fields[strings.ToLower(name)] {
+			pair = key + "=" + redacted
+		}
+		pairs = append(pairs, pair)
+	}
+	return strings.Join(pairs, "&")
+}
+
+func (t *loggingTransport) redactValue(v interface{}) interface{} {
+	switch e := v.(type) {
+	case map[string]interface{}:
+		for key, value := range e {
+			if t.fields[strings.ToLower(key)] {
+				e[key] = redacted
+				continue
+			}
+			e[key] = t.redactValue(value)
+		}
+	case []interface{}:
+		for i := range e {
+			e[i] = t.redactValue(e[i])
+		}
+	}
+	return v
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
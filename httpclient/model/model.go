@@ -1,8 +1,14 @@
 package model
 
 const (
-	ContentType    = "Content-Type"
-	FormUrlEncoded = "application/x-www-form-urlencoded"
-	Post           = "POST"
-	Get            = "GET"
+	ContentType     = "Content-Type"
+	Accept          = "Accept"
+	FormUrlEncoded  = "application/x-www-form-urlencoded"
+	ApplicationJSON = "application/json"
+	Post            = "POST"
+	Get             = "GET"
+	Put             = "PUT"
+	Patch           = "PATCH"
+	Delete          = "DELETE"
+	Head            = "HEAD"
 )
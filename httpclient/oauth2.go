@@ -0,0 +1,192 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/felipeflores/utils/ferrors"
+)
+
+// Token is an OAuth2 access token.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope,omitempty"`
+
+	// Expiry is computed from ExpiresIn when the token is received.
+	Expiry time.Time `json:"-"`
+}
+
+func (t *Token) valid(delta time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(delta).Before(t.Expiry)
+}
+
+// TokenConfig configures the token endpoint and the client credentials.
+type TokenConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// RefreshToken, when set, is used for the first token instead of
+	// the client credentials grant.
+	RefreshToken string
+	// Params are extra form parameters, such as audience.
+	Params map[string]string
+	// ExpiryDelta renews the token that long before it expires. Defaults to 30 seconds.
+	ExpiryDelta time.Duration
+	// FetchTimeout bounds each call to the token endpoint, which is shared by
+	// the callers and so not bound by their contexts. Defaults to 30 seconds.
+	FetchTimeout time.Duration
+}
+
+// TokenSource fetches OAuth2 tokens and caches them until shortly before expiry.
+// Concurrent callers share a single request to the token endpoint.
+type TokenSource struct {
+	client *HttpClient[Token]
+	config TokenConfig
+	group  singleflight.Group
+
+	mu           sync.Mutex
+	token        *Token
+	refreshToken string
+}
+
+// NewTokenSource creates a token source. The options configure the client
+// used to call the token endpoint.
+func NewTokenSource(c TokenConfig, opts ...Option) *TokenSource {
+	if c.ExpiryDelta <= 0 {
+		c.ExpiryDelta = 30 * time.Second
+	}
+	if c.FetchTimeout <= 0 {
+		c.FetchTimeout = 30 * time.Second
+	}
+	return &TokenSource{
+		client:       New[Token](opts...),
+		config:       c,
+		refreshToken: c.RefreshToken,
+	}
+}
+
+// Token returns the cached token or fetches a new one.
+func (s *TokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+	if token.valid(s.config.ExpiryDelta) {
+		return token, nil
+	}
+
+	// The fetch is detached from ctx, since the result is shared with other callers.
+	ch := s.group.DoChan("token", func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), s.config.FetchTimeout)
+		defer cancel()
+		return s.fetch(fetchCtx)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(*Token), nil
+	}
+}
+
+// Invalidate drops the cached token, so the next call fetches a new one.
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
+
+// Interceptor returns an interceptor that sets the bearer token on every request.
+// A 401 response invalidates the cached token.
+func (s *TokenSource) Interceptor() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := s.Token(req.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+			resp, err := next.RoundTrip(req)
+			if err == nil && resp.StatusCode == http.StatusUnauthorized {
+				s.Invalidate()
+			}
+			return resp, err
+		})
+	}
+}
+
+func (s *TokenSource) fetch(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	if s.token.valid(s.config.ExpiryDelta) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	refreshToken := s.refreshToken
+	s.mu.Unlock()
+
+	form := map[string]string{
+		"client_id":     s.config.ClientID,
+		"client_secret": s.config.ClientSecret,
+	}
+	if refreshToken != "" {
+		form["grant_type"] = "refresh_token"
+		form["refresh_token"] = refreshToken
+	} else {
+		form["grant_type"] = "client_credentials"
+	}
+	if len(s.config.Scopes) > 0 {
+		form["scope"] = strings.Join(s.config.Scopes, " ")
+	}
+	for key, value := range s.config.Params {
+		form[key] = value
+	}
+
+	var token Token
+	if err := s.client.PostFormUrlEncodedWithContext(ctx, s.config.TokenURL, form, &token); err != nil {
+		if refreshToken != "" && grantRejected(err) {
+			// The refresh token is no longer usable, the next fetch falls back to client credentials.
+			s.mu.Lock()
+			s.refreshToken = ""
+			s.mu.Unlock()
+		}
+		return nil, err
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	s.mu.Lock()
+	s.token = &token
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	s.mu.Unlock()
+	return &token, nil
+}
+
+// grantRejected reports whether the token endpoint rejected the grant, as opposed
+// to a transient failure, such as a network error or a 5xx response.
+func grantRejected(err error) bool {
+	var badRequest *ferrors.ErrBadRequest
+	var unauthorized *ferrors.ErrUnauthorized
+	return errors.As(err, &badRequest) || errors.As(err, &unauthorized)
+}
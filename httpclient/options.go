@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Option configures an HttpClient at construction time.
+type Option func(*options)
+
+type options struct {
+	retry   *RetryPolicy
+	breaker *BreakerConfig
+	logging *LogConfig
+
+	interceptors []Interceptor
+	transport    http.RoundTripper
+	timeout      time.Duration
+	tlsConfig    *tls.Config
+	rateLimit    *RateLimit
+	cache        CacheStore
+	hedge        *HedgePolicy
+	codecs       *Codecs
+	contentType  string
+	gzipRequests bool
+}
+
+// WithRetryPolicy enables retries for every call made by the client.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &p
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker per upstream host.
+func WithCircuitBreaker(c BreakerConfig) Option {
+	return func(o *options) {
+		o.breaker = &c
+	}
+}
+
+// WithLogging logs every request and response through the given logger.
+func WithLogging(c LogConfig) Option {
+	return func(o *options) {
+		o.logging = &c
+	}
+}
+
+// WithInterceptors appends interceptors to the client chain.
+// The first interceptor is the outermost one.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithTransport replaces http.DefaultTransport as the base transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithTimeout limits the time of each attempt, including reading the response body.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithTLSConfig sets the TLS config of the base transport,
+// as long as it is an *http.Transport.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = c
+	}
+}
+
+// WithRateLimit limits the rate and the concurrency of the calls to each host.
+func WithRateLimit(l RateLimit) Option {
+	return func(o *options) {
+		o.rateLimit = &l
+	}
+}
+
+// WithCache caches GET responses in store, following the HTTP caching headers.
+// The credentials set by the interceptors are part of the cache key, and the
+// responses of DoStream are never cached, so their bodies are not buffered.
+func WithCache(store CacheStore) Option {
+	return func(o *options) {
+		o.cache = store
+	}
+}
+
+// WithHedging hedges GET requests following the policy.
+func WithHedging(p HedgePolicy) Option {
+	return func(o *options) {
+		o.hedge = &p
+	}
+}
+
+// WithCodecs replaces the default codecs registry.
+func WithCodecs(c *Codecs) Option {
+	return func(o *options) {
+		o.codecs = c
+	}
+}
+
+// WithContentType sets the media type used to encode request bodies.
+// It must have a registered codec. Defaults to application/json.
+func WithContentType(mediaType string) Option {
+	return func(o *options) {
+		o.contentType = mediaType
+	}
+}
+
+// WithGzipRequests compresses request bodies with gzip, after the interceptors,
+// except those of requests whose context is marked by SkipRequestCompression.
+func WithGzipRequests() Option {
+	return func(o *options) {
+		o.gzipRequests = true
+	}
+}
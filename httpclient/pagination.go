@@ -0,0 +1,205 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/felipeflores/utils/httpclient/model"
+)
+
+// FetchPage gets the page at path and returns its items and the path of the
+// next page, which is empty on the last page.
+type FetchPage[T any] func(ctx context.Context, path string) (items []T, next string, err error)
+
+// Paginator yields the items of a paginated resource, fetching the pages lazily.
+//
+//	p := httpclient.NewPaginator(ctx, path, httpclient.LinkPages[Item](client, nil))
+//	for p.Next() {
+//		item := p.Item()
+//	}
+//	if err := p.Err(); err != nil {
+//	}
+type Paginator[T any] struct {
+	ctx   context.Context
+	fetch FetchPage[T]
+	next  string
+	items []T
+	item  T
+	err   error
+}
+
+// NewPaginator creates a paginator starting at path.
+func NewPaginator[T any](ctx context.Context, path string, fetch FetchPage[T]) *Paginator[T] {
+	return &Paginator[T]{
+		ctx:   ctx,
+		fetch: fetch,
+		next:  path,
+	}
+}
+
+// Next advances to the next item, fetching the next page when needed.
+// It returns false after the last item, on error or when the context is done.
+func (p *Paginator[T]) Next() bool {
+	for len(p.items) == 0 {
+		if p.err != nil || p.next == "" {
+			return false
+		}
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+
+		items, next, err := p.fetch(p.ctx, p.next)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.items, p.next = items, next
+		if len(items) == 0 {
+			p.next = ""
+		}
+	}
+
+	p.item, p.items = p.items[0], p.items[1:]
+	return true
+}
+
+// Item returns the current item.
+func (p *Paginator[T]) Item() T {
+	return p.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// All collects the remaining items.
+func (p *Paginator[T]) All() ([]T, error) {
+	items := make([]T, 0)
+	for p.Next() {
+		items = append(items, p.Item())
+	}
+	return items, p.Err()
+}
+
+// LinkPages fetches pages whose body is a JSON array of items and whose next
+// page is given by the RFC 5988 Link header with rel="next".
+func LinkPages[T any](s Sender, headers map[string]string) FetchPage[T] {
+	return func(ctx context.Context, path string) ([]T, string, error) {
+		r, err := Do[struct{}, []T](ctx, s, model.Get, path, headers, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		next := nextLink(r.Header)
+		if next == "" {
+			return r.Body, "", nil
+		}
+		next, err = resolve(path, next)
+		return r.Body, next, err
+	}
+}
+
+// CursorPages fetches pages decoded into P, from which extract returns the items
+// and the cursor of the next page, sent as the param query parameter.
+// An empty cursor ends the pagination.
+func CursorPages[P any, T any](s Sender, headers map[string]string, param string, extract func(page P) ([]T, string)) FetchPage[T] {
+	return func(ctx context.Context, path string) ([]T, string, error) {
+		r, err := Do[struct{}, P](ctx, s, model.Get, path, headers, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		items, cursor := extract(r.Body)
+		if cursor == "" {
+			return items, "", nil
+		}
+		next, err := setQuery(path, map[string]string{param: cursor})
+		return items, next, err
+	}
+}
+
+// OffsetPages fetches pages decoded into P, from which extract returns the items,
+// sending the offset and limit query parameters. A page with less than limit
+// items ends the pagination.
+func OffsetPages[P any, T any](s Sender, headers map[string]string, offsetParam, limitParam string, limit int, extract func(page P) []T) FetchPage[T] {
+	return func(ctx context.Context, path string) ([]T, string, error) {
+		u, err := url.Parse(path)
+		if err != nil {
+			return nil, "", err
+		}
+		offset, _ := strconv.Atoi(u.Query().Get(offsetParam))
+
+		path, err = setQuery(path, map[string]string{
+			offsetParam: strconv.Itoa(offset),
+			limitParam:  strconv.Itoa(limit),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		r, err := Do[struct{}, P](ctx, s, model.Get, path, headers, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		items := extract(r.Body)
+		if len(items) < limit {
+			return items, "", nil
+		}
+		next, err := setQuery(path, map[string]string{offsetParam: strconv.Itoa(offset + len(items))})
+		return items, next, err
+	}
+}
+
+// nextLink returns the target of the Link header with rel="next".
+func nextLink(header http.Header) string {
+	for _, v := range header.Values("Link") {
+		for _, link := range strings.Split(v, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(key, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// resolve resolves ref, that may be relative, against base.
+func resolve(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+func setQuery(path string, params map[string]string) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for key, value := range params {
+		q.Set(key, value)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit limits the calls made to each upstream host.
+type RateLimit struct {
+	// Rate is the number of requests per second. Zero means no limit.
+	Rate float64
+	// Burst is the bucket size. Defaults to 1.
+	Burst int
+	// MaxInFlight caps the concurrent requests. Zero means no limit.
+	MaxInFlight int
+	// MinRate is the floor of the rate when it is reduced after a 429 response.
+	// Defaults to a tenth of Rate.
+	MinRate float64
+}
+
+// ErrRateLimited is returned when the request cannot be sent before the context deadline.
+type ErrRateLimited struct {
+	Host string
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limit for host %s would exceed the context deadline", e.Host)
+}
+
+// defaultPause is used when a 429 response has no Retry-After header.
+const defaultPause = time.Second
+
+type limiterTransport struct {
+	next   http.RoundTripper
+	config RateLimit
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+type hostLimiter struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+func newLimiterTransport(next http.RoundTripper, c RateLimit) *limiterTransport {
+	if c.Burst < 1 {
+		c.Burst = 1
+	}
+	if c.MinRate <= 0 {
+		c.MinRate = c.Rate / 10
+	}
+	return &limiterTransport{
+		next:   next,
+		config: c,
+		hosts:  make(map[string]*hostLimiter),
+	}
+}
+
+func (t *limiterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	hl := t.host(host)
+
+	if err := hl.waitPause(ctx, host); err != nil {
+		return nil, err
+	}
+	if err := hl.limiter.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &ErrRateLimited{Host: host}
+	}
+
+	release := func() {}
+	if hl.slots != nil {
+		select {
+		case hl.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-hl.slots }) }
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	t.adapt(hl, resp)
+	// The slot is held until the response body is closed.
+	resp.Body = releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// adapt pauses the host and halves its rate on 429 responses,
+// and slowly restores the rate on other responses.
+// Without a Rate, only the pause applies.
+func (t *limiterTransport) adapt(hl *hostLimiter, resp *http.Response) {
+	limited := t.config.Rate > 0
+	limit := float64(hl.limiter.Limit())
+	if resp.StatusCode != http.StatusTooManyRequests {
+		if limited && limit < t.config.Rate {
+			hl.limiter.SetLimit(rate.Limit(minFloat(limit*1.1, t.config.Rate)))
+		}
+		return
+	}
+
+	pause, ok := retryAfter(resp)
+	if !ok {
+		pause = defaultPause
+	}
+	hl.mu.Lock()
+	if until := time.Now().Add(pause); until.After(hl.pausedUntil) {
+		hl.pausedUntil = until
+	}
+	hl.mu.Unlock()
+	if limited {
+		hl.limiter.SetLimit(rate.Limit(maxFloat(limit/2, t.config.MinRate)))
+	}
+}
+
+func (t *limiterTransport) host(host string) *hostLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hl, ok := t.hosts[host]
+	if !ok {
+		limit := rate.Inf
+		if t.config.Rate > 0 {
+			limit = rate.Limit(t.config.Rate)
+		}
+		hl = &hostLimiter{limiter: rate.NewLimiter(limit, t.config.Burst)}
+		if t.config.MaxInFlight > 0 {
+			hl.slots = make(chan struct{}, t.config.MaxInFlight)
+		}
+		t.hosts[host] = hl
+	}
+	return hl
+}
+
+// waitPause blocks while the host is paused by a 429 response,
+// failing fast when the pause goes beyond the context deadline.
+func (hl *hostLimiter) waitPause(ctx context.Context, host string) error {
+	hl.mu.Lock()
+	wait := time.Until(hl.pausedUntil)
+	hl.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if exceedsDeadline(ctx, wait) {
+		return &ErrRateLimited{Host: host}
+	}
+	return sleep(ctx, wait)
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
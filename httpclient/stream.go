@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+
+	"github.com/felipeflores/utils/httpclient/model"
+)
+
+// maxStreamErrorBody caps how much of an error body is read by DoStream.
+const maxStreamErrorBody = 64 << 10
+
+// MultipartFile is a file part of a multipart/form-data upload.
+type MultipartFile struct {
+	Field       string
+	FileName    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// DoStream sends body as is, without buffering, and returns the response
+// with its body open, the caller must close it. It bypasses the cache.
+// Responses with status 4xx or 5xx are closed and returned along with the matching ferrors error.
+// Only bodies that can be rewound, such as *bytes.Reader or *strings.Reader, are retried.
+func (h *HttpClient[T]) DoStream(ctx context.Context, method, path string, headers map[string]string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, skipCacheKey{}, true), method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := h.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxStreamErrorBody))
+		if err != nil {
+			return resp, err
+		}
+		return resp, responseError(resp, b)
+	}
+	return resp, nil
+}
+
+// PostMultipart uploads fields and files as multipart/form-data, streaming the files,
+// and decodes the response into response, when not nil.
+func (h *HttpClient[T]) PostMultipart(ctx context.Context, path string, headers map[string]string, fields map[string]string, files []MultipartFile, response *T) error {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, fields, files))
+	}()
+
+	hs := map[string]string{model.ContentType: mw.FormDataContentType()}
+	for key, value := range headers {
+		hs[key] = value
+	}
+
+	resp, err := h.DoStream(ctx, model.Post, path, hs, pr)
+	pr.Close()
+	if err != nil {
+		return err
+	}
+	if response == nil {
+		drain(resp)
+		return nil
+	}
+	return DecodeInto(resp, response)
+}
+
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files []MultipartFile) error {
+	for key, value := range fields {
+		if err := mw.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", multipart.FileContentDisposition(f.Field, f.FileName))
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header.Set(model.ContentType, contentType)
+
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, f.Reader); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// DecodeInto decodes the JSON response body into v, reading it as a stream,
+// and closes the body.
+func DecodeInto[R any](resp *http.Response, v *R) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// JSONLines iterates over a newline-delimited JSON response body,
+// decoding one item at a time.
+//
+//	lines := httpclient.NewJSONLines[Item](resp)
+//	defer lines.Close()
+//	for lines.Next() {
+//		item := lines.Item()
+//	}
+//	if err := lines.Err(); err != nil {
+//	}
+type JSONLines[R any] struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+	item R
+	err  error
+}
+
+// NewJSONLines returns an iterator over the body of resp.
+func NewJSONLines[R any](resp *http.Response) *JSONLines[R] {
+	return &JSONLines[R]{
+		body: resp.Body,
+		dec:  json.NewDecoder(resp.Body),
+	}
+}
+
+// Next decodes the next item. It returns false at the end of the body or on error.
+func (l *JSONLines[R]) Next() bool {
+	if l.err != nil {
+		return false
+	}
+
+	var item R
+	if err := l.dec.Decode(&item); err != nil {
+		if err != io.EOF {
+			l.err = err
+		}
+		return false
+	}
+	l.item = item
+	return true
+}
+
+// Item returns the last decoded item.
+func (l *JSONLines[R]) Item() R {
+	return l.item
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (l *JSONLines[R]) Err() error {
+	return l.err
+}
+
+// Close closes the response body.
+func (l *JSONLines[R]) Close() error {
+	return l.body.Close()
+}
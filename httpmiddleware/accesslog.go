@@ -0,0 +1,174 @@
+package httpmiddleware
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/felipeflores/utils/log"
+)
+
+// AccessLogConfig configures the access log.
+// Requests are logged as info, 4xx and slow requests as warn, and 5xx as error.
+type AccessLogConfig struct {
+	// Logger defaults to the zap global logger, set by zap.ReplaceGlobals.
+	Logger log.Logger
+	// SampleRate is the fraction (0 to 1) of the info requests that are logged.
+	// Warn and error requests are always logged. Zero logs every request.
+	SampleRate float64
+	// ExcludePaths lists the paths, or route templates, that are never logged,
+	// such as health checks. A trailing * matches any path with that prefix.
+	ExcludePaths []string
+	// SlowThreshold logs the requests that take longer as warn. Zero disables it.
+	SlowThreshold time.Duration
+	// RequestIDHeader is the header of the request ID. Defaults to X-Request-Id.
+	RequestIDHeader string
+	// TrustProxy takes the remote IP from the X-Forwarded-For
+	// or X-Real-Ip headers, when present.
+	TrustProxy bool
+}
+
+// AccessLog logs every request, after it is served, with its method, route,
+// status, latency, bytes in and out, remote IP, user agent and request ID.
+// The route is the gorilla/mux template, so it is only known when the
+// middleware is added with Router.Use; otherwise it is left out.
+func AccessLog(c AccessLogConfig) func(http.Handler) http.Handler {
+	if c.Logger == nil {
+		c.Logger = zap.L()
+	}
+	if c.RequestIDHeader == "" {
+		c.RequestIDHeader = "X-Request-Id"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			route := routeTemplate(req)
+			if c.excluded(req.URL.Path, route) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			start := time.Now()
+			resp := &responseWriter{ResponseWriter: w}
+			body := &countingReader{ReadCloser: req.Body}
+			if req.Body != nil && req.Body != http.NoBody {
+				req.Body = body
+			}
+
+			panicked := true
+			defer func() {
+				status := resp.status
+				switch {
+				case status != 0:
+				case resp.hijacked:
+					status = http.StatusSwitchingProtocols
+				case panicked:
+					status = http.StatusInternalServerError
+				default:
+					status = http.StatusOK
+				}
+				c.log(req, route, status, time.Since(start), body.n, resp.bytes)
+			}()
+			next.ServeHTTP(resp, req)
+			panicked = false
+		})
+	}
+}
+
+func (c AccessLogConfig) log(req *http.Request, route string, status int, latency time.Duration, in, out int64) {
+	slow := c.SlowThreshold > 0 && latency > c.SlowThreshold
+	if status < http.StatusBadRequest && !slow && c.SampleRate > 0 && c.SampleRate < 1 && rand.Float64() >= c.SampleRate {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("path", req.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("latency", latency),
+		zap.Int64("bytes_in", in),
+		zap.Int64("bytes_out", out),
+		zap.String("remote_ip", c.remoteIP(req)),
+		zap.String("user_agent", req.UserAgent()),
+	}
+	if route != "" {
+		fields = append(fields, zap.String("route", route))
+	}
+	if id := req.Header.Get(c.RequestIDHeader); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if slow {
+		fields = append(fields, zap.Bool("slow", true))
+	}
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		c.Logger.Error("access", fields...)
+	case status >= http.StatusBadRequest || slow:
+		c.Logger.Warn("access", fields...)
+	default:
+		c.Logger.Info("access", fields...)
+	}
+}
+
+func (c AccessLogConfig) excluded(path, route string) bool {
+	for _, p := range c.ExcludePaths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if p == path || (route != "" && p == route) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c AccessLogConfig) remoteIP(req *http.Request) string {
+	if c.TrustProxy {
+		if v := req.Header.Get("X-Forwarded-For"); v != "" {
+			ip, _, _ := strings.Cut(v, ",")
+			return strings.TrimSpace(ip)
+		}
+		if v := req.Header.Get("X-Real-Ip"); v != "" {
+			return v
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// routeTemplate returns the path template of the gorilla/mux route of req, if any.
+func routeTemplate(req *http.Request) string {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return ""
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return tmpl
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
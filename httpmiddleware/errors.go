@@ -1,56 +1,113 @@
 package httpmiddleware
 
 import (
-	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/felipeflores/utils/internal/errkind"
 )
 
 type ErrorResponse struct {
-	Timestamp time.Time `json:"timestamp"`
-	Message   string    `json:"message"`
-	Fields    []Field   `json:"fields,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+	Message   string      `json:"message"`
+	Code      string      `json:"code,omitempty"`
+	ErrorID   string      `json:"error_id,omitempty"`
+	Fields    []Field     `json:"fields,omitempty"`
+	Errors    []ItemError `json:"errors,omitempty"`
 }
 
 type Field struct {
 	Name    string `json:"name"`
 	Message string `json:"message"`
+
+	err error
 }
 
-type (
-	badrequest interface {
-		BadRequest() bool
-		GetFields() map[string]error
-	}
-	notfound interface {
-		NotFound() bool
+// ItemError is the error of an item of a multi error.
+type ItemError struct {
+	Path    string  `json:"path"`
+	Status  int     `json:"status"`
+	Message string  `json:"message"`
+	Code    string  `json:"code,omitempty"`
+	ErrorID string  `json:"error_id,omitempty"`
+	Fields  []Field `json:"fields,omitempty"`
+}
+
+// StatusMapper returns the HTTP status of an error of the chain,
+// or false when it does not know the error.
+type StatusMapper func(err error) (int, bool)
+
+// kindStatus is the HTTP status of each ferrors kind.
+var kindStatus = map[errkind.Kind]int{
+	errkind.BadRequest:           http.StatusBadRequest,
+	errkind.NotFound:             http.StatusNotFound,
+	errkind.Unauthorized:         http.StatusUnauthorized,
+	errkind.Forbidden:            http.StatusForbidden,
+	errkind.NotAcceptable:        http.StatusNotAcceptable,
+	errkind.RequestTimeout:       http.StatusRequestTimeout,
+	errkind.Conflict:             http.StatusConflict,
+	errkind.Gone:                 http.StatusGone,
+	errkind.PreconditionFailed:   http.StatusPreconditionFailed,
+	errkind.PayloadTooLarge:      http.StatusRequestEntityTooLarge,
+	errkind.UnsupportedMediaType: http.StatusUnsupportedMediaType,
+	errkind.UnprocessableEntity:  http.StatusUnprocessableEntity,
+	errkind.TooManyRequests:      http.StatusTooManyRequests,
+	errkind.InternalServer:       http.StatusInternalServerError,
+	errkind.BadGateway:           http.StatusBadGateway,
+	errkind.ServiceUnavailable:   http.StatusServiceUnavailable,
+	errkind.GatewayTimeout:       http.StatusGatewayTimeout,
+}
+
+// resolveStatus walks the chain of err and returns the status of the innermost
+// error known by the mappers or by the ferrors kinds, and the error itself.
+// Errors not known at all are reported as 500, along with err.
+func resolveStatus(err error, mappers []StatusMapper) (int, error) {
+	errs := errkind.Chain(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		if e, ok := errs[i].(errkind.Multier); ok {
+			return multiStatus(e, mappers), errs[i]
+		}
+		for _, mapper := range mappers {
+			if status, ok := mapper(errs[i]); ok {
+				return status, errs[i]
+			}
+		}
+		if status, ok := kindStatus[errkind.Of(errs[i])]; ok {
+			return status, errs[i]
+		}
 	}
-	unauthorized interface {
-		Unauthorized() bool
+	return http.StatusInternalServerError, err
+}
+
+// multiStatus returns 207 Multi-Status when some items of e succeeded. Otherwise,
+// it returns the status of the items when they all have the same, or the generic
+// status of the most severe class, such as 400 for 404 and 409 items.
+func multiStatus(e errkind.Multier, mappers []StatusMapper) int {
+	if e.Partial() {
+		return http.StatusMultiStatus
 	}
-	notacceptable interface {
-		NotAcceptable() bool
+	status := 0
+	for _, item := range e.Items() {
+		s, _ := resolveStatus(item.Err, mappers)
+		switch {
+		case status == 0, s/100 > status/100:
+			status = s
+		case s/100 == status/100 && s != status:
+			status = s / 100 * 100
+		}
 	}
-
-	conflict interface {
-		Conflict() bool
+	if status == 0 {
+		return http.StatusInternalServerError
 	}
-)
+	return status
+}
 
-func httpStatusCode(err error) int {
-	switch e := err.(type) {
-	case badrequest:
-		fmt.Println(e.GetFields())
-		return http.StatusBadRequest
-	case notfound:
-		return http.StatusNotFound
-	case unauthorized:
-		return http.StatusUnauthorized
-	case notacceptable:
-		return http.StatusNotAcceptable
-	case conflict:
-		return http.StatusConflict
-	default:
-		return http.StatusInternalServerError
+// setErrorHeaders sets the response headers of err, such as Retry-After.
+func setErrorHeaders(resp http.ResponseWriter, err error) {
+	if e, ok := err.(errkind.RetryAfterer); ok && e.RetryAfter() > 0 {
+		seconds := int64(math.Ceil(e.RetryAfter().Seconds()))
+		resp.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
 	}
 }
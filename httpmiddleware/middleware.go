@@ -6,43 +6,161 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/felipeflores/utils/i18n"
+	"github.com/felipeflores/utils/internal/errkind"
+	"github.com/felipeflores/utils/log"
 )
 
-type Middleware struct{}
+type Middleware struct {
+	problem ProblemConfig
+	mappers []StatusMapper
+	safe    bool
+	logger  log.Logger
+	catalog *i18n.Catalog
 
-func New() *Middleware {
-	return &Middleware{}
+	repanicAbort bool
+}
+
+func New(opts ...Option) *Middleware {
+	m := &Middleware{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
+// HandlerError writes the error returned by h. A panic of h is recovered
+// and written as an internal server error.
 func (m *Middleware) HandlerError(h func(resp http.ResponseWriter, req *http.Request) error) http.Handler {
-	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp := &responseWriter{ResponseWriter: w}
+		defer m.recoverPanic(resp, req)
+
 		ctx := req.Context()
 		err := h(resp, req.WithContext(ctx))
-		if err != nil {
-			httpStatus := httpStatusCode(err)
-			message := err.Error()
+		if err != nil && !resp.hijacked {
+			m.writeError(resp, req, err)
+		}
+	})
+}
 
-			fmt.Println(httpStatus, message)
-			resp.WriteHeader(httpStatus)
+// writeError writes err as an ErrorResponse, or as problem details when configured.
+// The status, code, fields and details come from the innermost known error of the chain.
+func (m *Middleware) writeError(resp http.ResponseWriter, req *http.Request, err error) {
+	var lang string
+	if m.catalog != nil {
+		lang = m.catalog.Match(req.Header.Get("Accept-Language"))
+		if lang != "" {
+			resp.Header().Set("Content-Language", lang)
+		}
+	}
+	httpStatus, kind, errorResponse := m.errorResponse(req, lang, err)
 
-			errorResponse := ErrorResponse{
-				Timestamp: time.Now(),
-				Message:   message,
-			}
-			if httpStatus == 400 {
-				switch e := err.(type) {
-				case badrequest:
-					errorResponse.Fields = make([]Field, 0)
-					for key, v := range e.GetFields() {
-						f := Field{Name: key, Message: v.Error()}
-						errorResponse.Fields = append(errorResponse.Fields, f)
-					}
-
-				}
+	var payload interface{} = errorResponse
+	contentType := "application/json"
+	if m.problem.wants(req) {
+		var details map[string]interface{}
+		if e, ok := kind.(errkind.Detailer); ok && !(m.safe && httpStatus >= http.StatusInternalServerError) {
+			details = e.Details()
+		}
+		payload = m.problem.newProblem(req, httpStatus, errorResponse, details)
+		contentType = ProblemContentType
+	}
+
+	setErrorHeaders(resp, kind)
+	resp.Header().Set("Content-Type", contentType)
+	resp.WriteHeader(httpStatus)
+	if err := json.NewEncoder(resp).Encode(payload); err != nil && m.logger != nil {
+		m.logger.Error("encode error response", zap.Error(err), zap.String("path", req.URL.Path))
+	}
+}
+
+// errorResponse resolves the status of err and builds its ErrorResponse,
+// with an entry for each item of a multi error.
+func (m *Middleware) errorResponse(req *http.Request, lang string, err error) (int, error, ErrorResponse) {
+	httpStatus, kind := resolveStatus(err, m.mappers)
+	message := err.Error()
+
+	errorResponse := ErrorResponse{
+		Timestamp: time.Now(),
+		Message:   message,
+	}
+	if m.safe {
+		m.hideDetails(req, httpStatus, err, kind, &errorResponse)
+	} else {
+		fmt.Println(httpStatus, message)
+	}
+	if e, ok := kind.(errkind.Coder); ok {
+		errorResponse.Code = e.Code()
+	}
+	if e, ok := kind.(errkind.Fielder); ok && httpStatus < http.StatusInternalServerError {
+		errorResponse.Fields = make([]Field, 0)
+		for key, v := range e.GetFields() {
+			f := Field{Name: key, Message: v.Error(), err: v}
+			errorResponse.Fields = append(errorResponse.Fields, f)
+		}
+	}
+	if e, ok := kind.(errkind.Multier); ok {
+		if !(m.safe && httpStatus >= http.StatusInternalServerError) {
+			errorResponse.Message = http.StatusText(httpStatus)
+			if msg, ok := kind.(errkind.Messager); ok && msg.Msg() != "" {
+				errorResponse.Message = msg.Msg()
 			}
-			SendJSON(resp, errorResponse)
 		}
-	})
+		errorResponse.Errors = make([]ItemError, 0, len(e.Items()))
+		for _, item := range e.Items() {
+			status, _, r := m.errorResponse(req, lang, item.Err)
+			errorResponse.Errors = append(errorResponse.Errors, ItemError{
+				Path:    item.Path,
+				Status:  status,
+				Message: r.Message,
+				Code:    r.Code,
+				ErrorID: r.ErrorID,
+				Fields:  r.Fields,
+			})
+		}
+	}
+	if m.catalog != nil {
+		m.translate(lang, httpStatus, kind, &errorResponse)
+	}
+	return httpStatus, kind, errorResponse
+}
+
+// hideDetails replaces the message of 5xx errors with a generic one and an error ID,
+// logging the full error, and the message of 4xx errors with the user facing one.
+func (m *Middleware) hideDetails(req *http.Request, status int, err, kind error, errResp *ErrorResponse) {
+	if status < http.StatusInternalServerError {
+		if e, ok := kind.(errkind.Messager); ok {
+			errResp.Message = e.Msg()
+		}
+		if m.logger != nil {
+			m.logger.Warn(err.Error(),
+				zap.Int("status", status),
+				zap.String("method", req.Method),
+				zap.String("path", req.URL.Path),
+			)
+		}
+		return
+	}
+
+	errResp.ErrorID = req.Header.Get("X-Request-Id")
+	if errResp.ErrorID == "" {
+		errResp.ErrorID = uuid.New().String()
+	}
+	errResp.Message = http.StatusText(status)
+	if m.logger != nil {
+		m.logger.Error(err.Error(),
+			zap.String("error_id", errResp.ErrorID),
+			zap.Int("status", status),
+			zap.String("method", req.Method),
+			zap.String("path", req.URL.Path),
+			zap.String("stack", errkind.StackTrace(err)),
+		)
+	}
 }
 
 func SendJSON(resp http.ResponseWriter, payload interface{}) error {
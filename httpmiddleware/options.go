@@ -0,0 +1,65 @@
+package httpmiddleware
+
+import (
+	"github.com/felipeflores/utils/i18n"
+	"github.com/felipeflores/utils/log"
+)
+
+// Option configures a Middleware.
+type Option func(*Middleware)
+
+// WithProblemDetails renders errors as RFC 7807 problem details,
+// always or only when the request accepts application/problem+json.
+func WithProblemDetails(c ProblemConfig) Option {
+	return func(m *Middleware) {
+		m.problem = c
+	}
+}
+
+// WithStatusMapper registers a mapper consulted, before the ferrors kinds,
+// for every error of the chain. Mappers are called in the order they are registered.
+func WithStatusMapper(mapper StatusMapper) Option {
+	return func(m *Middleware) {
+		m.mappers = append(m.mappers, mapper)
+	}
+}
+
+// WithSafeErrors hides internal details from the error responses:
+// 5xx responses get a generic message and an error ID, while the full error,
+// with its stack trace, is logged with that ID; 4xx responses get the
+// user facing message of the ferrors kind instead of the message of its cause.
+// The error ID is the request X-Request-Id header, or a new UUID.
+func WithSafeErrors(logger log.Logger) Option {
+	return func(m *Middleware) {
+		m.safe = true
+		if logger != nil {
+			m.logger = logger
+		}
+	}
+}
+
+// WithCatalog translates the error messages to the language of the request
+// Accept-Language header. The message of a ferrors kind is translated by its
+// message key, an ozzo-validation field error by its code and, in safe mode,
+// the generic message of 5xx responses by the "status.<code>" key, such as status.500.
+func WithCatalog(c *i18n.Catalog) Option {
+	return func(m *Middleware) {
+		m.catalog = c
+	}
+}
+
+// WithLogger sets the logger of the recovered panics and, in safe mode, of the errors.
+// Recovered panics are logged to the zap global logger when it is not set.
+func WithLogger(logger log.Logger) Option {
+	return func(m *Middleware) {
+		m.logger = logger
+	}
+}
+
+// WithAbortRepanic panics again with http.ErrAbortHandler when a handler panics with it,
+// so the server aborts the response, instead of writing it as an internal server error.
+func WithAbortRepanic() Option {
+	return func(m *Middleware) {
+		m.repanicAbort = true
+	}
+}
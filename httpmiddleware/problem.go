@@ -0,0 +1,130 @@
+package httpmiddleware
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemMode selects when errors are rendered as problem details.
+type ProblemMode int
+
+const (
+	// ProblemNever always renders the ErrorResponse.
+	ProblemNever ProblemMode = iota
+	// ProblemOnAccept renders problem details when the request accepts application/problem+json.
+	ProblemOnAccept
+	// ProblemAlways always renders problem details.
+	ProblemAlways
+)
+
+// ProblemConfig configures the rendering of problem details.
+type ProblemConfig struct {
+	Mode ProblemMode
+	// TypeBaseURL is joined with the error code to build the problem type URI.
+	// When empty, or the error has no code, the type is about:blank.
+	TypeBaseURL string
+}
+
+// Problem is an RFC 7807 (RFC 9457) problem details object.
+// Extensions are rendered as top level members.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code,omitempty"`
+	ErrorID       string         `json:"error_id,omitempty"`
+	Timestamp     time.Time      `json:"timestamp"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+	Errors        []ItemError    `json:"errors,omitempty"`
+
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// InvalidParam is a field error of a problem.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// MarshalJSON renders the extensions along with the standard members,
+// which take precedence.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	b, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions))
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	var standard map[string]interface{}
+	if err := json.Unmarshal(b, &standard); err != nil {
+		return nil, err
+	}
+	for key, value := range standard {
+		members[key] = value
+	}
+	return json.Marshal(members)
+}
+
+// newProblem converts an error response into problem details.
+func (c ProblemConfig) newProblem(req *http.Request, status int, errResp ErrorResponse, details map[string]interface{}) Problem {
+	p := Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     errResp.Message,
+		Instance:   req.URL.RequestURI(),
+		Code:       errResp.Code,
+		ErrorID:    errResp.ErrorID,
+		Timestamp:  errResp.Timestamp,
+		Errors:     errResp.Errors,
+		Extensions: details,
+	}
+	if c.TypeBaseURL != "" && errResp.Code != "" {
+		p.Type = strings.TrimSuffix(c.TypeBaseURL, "/") + "/" + errResp.Code
+	}
+	for _, f := range errResp.Fields {
+		p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: f.Name, Reason: f.Message})
+	}
+	return p
+}
+
+// wants reports whether the error of req is rendered as problem details.
+func (c ProblemConfig) wants(req *http.Request) bool {
+	switch c.Mode {
+	case ProblemAlways:
+		return true
+	case ProblemOnAccept:
+		return accepts(req, ProblemContentType)
+	default:
+		return false
+	}
+}
+
+// accepts reports whether the Accept header lists mediaType with a non zero quality.
+func accepts(req *http.Request, mediaType string) bool {
+	for _, v := range req.Header.Values("Accept") {
+		for _, part := range strings.Split(v, ",") {
+			t, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil || !strings.EqualFold(t, mediaType) {
+				continue
+			}
+			if q := params["q"]; q == "0" || q == "0.0" || q == "0.00" || q == "0.000" {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package httpmiddleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+
+	"github.com/felipeflores/utils/ferrors"
+)
+
+// recoverPanic converts a panic of the handler into an internal server error,
+// logged with its stack trace, by default to the zap global logger, and written
+// as any other error, unless the response was already written.
+// http.ErrAbortHandler is panicked again when WithAbortRepanic is set.
+func (m *Middleware) recoverPanic(resp *responseWriter, req *http.Request) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if r == http.ErrAbortHandler && m.repanicAbort {
+		panic(r)
+	}
+
+	// The panic value is formatted, not wrapped, so a ferrors kind panicked
+	// by the handler is not mistaken for the status of the response.
+	err := fmt.Errorf("panic: %v", r)
+	logger := m.logger
+	if logger == nil {
+		logger = zap.L()
+	}
+	logger.Error(err.Error(),
+		zap.String("method", req.Method),
+		zap.String("path", req.URL.Path),
+		zap.String("stack", string(debug.Stack())),
+	)
+
+	if resp.written() {
+		return
+	}
+	m.writeError(resp, req, ferrors.NewInternalServer(err))
+}
@@ -0,0 +1,41 @@
+package httpmiddleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/felipeflores/utils/ferrors"
+)
+
+// maxSignedBody caps the size of a request body read to verify its signature.
+const maxSignedBody = 1 << 20
+
+// SignatureVerifier verifies the signature of a request body,
+// such as the webhook.Verifier.
+type SignatureVerifier interface {
+	Verify(header http.Header, body []byte) error
+}
+
+// VerifySignature is HandlerError for handlers that only accept signed requests.
+// The body is verified before h is called, and is still readable by h.
+// Verification errors are written as any other error returned by h.
+// Bodies larger than 1 MiB are rejected as payload too large.
+func (m *Middleware) VerifySignature(v SignatureVerifier, h func(resp http.ResponseWriter, req *http.Request) error) http.Handler {
+	return m.HandlerError(func(resp http.ResponseWriter, req *http.Request) error {
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxSignedBody+1))
+		if err != nil {
+			return err
+		}
+		if len(body) > maxSignedBody {
+			return ferrors.NewPayloadTooLarge(fmt.Errorf("signed body exceeds %d bytes", maxSignedBody))
+		}
+		if err := v.Verify(req.Header, body); err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return h(resp, req)
+	})
+}
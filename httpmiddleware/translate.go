@@ -0,0 +1,49 @@
+package httpmiddleware
+
+import (
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/felipeflores/utils/internal/errkind"
+)
+
+// translate replaces the message and the field messages of errResp with the ones
+// of the catalog in lang. Messages without a translation are kept as they are.
+func (m *Middleware) translate(lang string, status int, kind error, errResp *ErrorResponse) {
+	if m.safe && status >= http.StatusInternalServerError {
+		if msg, ok := m.catalog.Translate(lang, fmt.Sprintf("status.%d", status), nil); ok {
+			errResp.Message = msg
+		}
+	} else if e, ok := kind.(errkind.MessageKeyer); ok {
+		if key, params := e.MessageKey(); key != "" {
+			if msg, ok := m.catalog.Translate(lang, key, params); ok {
+				errResp.Message = msg
+			}
+		}
+	}
+
+	for i, f := range errResp.Fields {
+		if msg, ok := m.translateField(lang, f.err); ok {
+			errResp.Fields[i].Message = msg
+		}
+	}
+}
+
+// translateField translates an ozzo-validation error by its code,
+// such as validation_required, or a ferrors error by its message key.
+func (m *Middleware) translateField(lang string, err error) (string, bool) {
+	switch e := err.(type) {
+	case validation.Error:
+		return m.catalog.Translate(lang, e.Code(), e.Params())
+	case errkind.MessageKeyer:
+		key, params := e.MessageKey()
+		if key == "" {
+			return "", false
+		}
+		return m.catalog.Translate(lang, key, params)
+	default:
+		return "", false
+	}
+}
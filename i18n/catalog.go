@@ -0,0 +1,220 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Catalog holds message templates by language and key.
+// Templates use the text/template syntax, as ozzo-validation does,
+// so "must be at least {{.min}}" is rendered with the min param.
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[string]*template.Template
+	// tags keeps the languages as they were added, by normalized tag.
+	tags map[string]string
+}
+
+// NewCatalog returns an empty catalog that falls back to the given language.
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: normalize(fallback),
+		messages: make(map[string]map[string]*template.Template),
+		tags:     map[string]string{normalize(fallback): strings.TrimSpace(fallback)},
+	}
+}
+
+// Add adds the messages of a language, replacing the keys it already has.
+func (c *Catalog) Add(lang string, messages map[string]string) error {
+	parsed := make(map[string]*template.Template, len(messages))
+	for key, message := range messages {
+		t, err := template.New(key).Option("missingkey=zero").Parse(message)
+		if err != nil {
+			return fmt.Errorf("i18n: parse %s message %q: %w", lang, key, err)
+		}
+		parsed[key] = t
+	}
+
+	tag := normalize(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[tag] == nil {
+		c.messages[tag] = parsed
+		c.tags[tag] = strings.TrimSpace(lang)
+		return nil
+	}
+	for key, t := range parsed {
+		c.messages[tag][key] = t
+	}
+	return nil
+}
+
+// Load adds the messages of a YAML or JSON file named after its language,
+// such as pt-BR.yaml or es.json. Nested keys are joined by dots.
+func (c *Catalog) Load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw interface{}
+	ext := filepath.Ext(path)
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &raw)
+	case ".json":
+		err = json.Unmarshal(b, &raw)
+	default:
+		return fmt.Errorf("i18n: unsupported file %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: load %s: %w", path, err)
+	}
+
+	messages := make(map[string]string)
+	flatten("", raw, messages)
+	return c.Add(strings.TrimSuffix(filepath.Base(path), ext), messages)
+}
+
+// LoadDir loads every YAML and JSON file of dir.
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if err := c.Load(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the language of the catalog that best fits an Accept-Language
+// header, trying each tag and then its base language, by quality.
+// It returns the fallback language when none fits.
+func (c *Catalog) Match(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if _, ok := c.messages[tag]; ok {
+			return c.tags[tag]
+		}
+		if base := baseLanguage(tag); base != tag {
+			if _, ok := c.messages[base]; ok {
+				return c.tags[base]
+			}
+		}
+	}
+	return c.tags[c.fallback]
+}
+
+// Translate renders the message of key in lang, its base language
+// or the fallback language, whichever has it first.
+func (c *Catalog) Translate(lang, key string, params map[string]interface{}) (string, bool) {
+	lang = normalize(lang)
+	c.mu.RLock()
+	t := c.lookup(key, lang, baseLanguage(lang), c.fallback)
+	c.mu.RUnlock()
+	if t == nil {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, params); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+func (c *Catalog) lookup(key string, langs ...string) *template.Template {
+	for _, lang := range langs {
+		if t, ok := c.messages[lang][key]; ok {
+			return t
+		}
+	}
+	return nil
+}
+
+// parseAcceptLanguage returns the tags of the header sorted by quality.
+// Tags with quality 0 and the * wildcard are left out.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalize(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(tag, "-")
+	return base
+}
+
+// normalize lowercases a language tag, since tags are case insensitive.
+func normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+func flatten(prefix string, v interface{}, out map[string]string) {
+	join := func(key interface{}) string {
+		if prefix == "" {
+			return fmt.Sprint(key)
+		}
+		return prefix + "." + fmt.Sprint(key)
+	}
+	switch m := v.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range m {
+			flatten(join(key), value, out)
+		}
+	case map[string]interface{}:
+		for key, value := range m {
+			flatten(join(key), value, out)
+		}
+	case nil:
+	default:
+		out[prefix] = fmt.Sprint(m)
+	}
+}
@@ -0,0 +1,219 @@
+// Package errkind classifies the errors of the ferrors kinds, through the marker
+// methods they implement, and walks error chains. It is shared by the HTTP and
+// gRPC middlewares, so both map the same errors the same way.
+package errkind
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/felipeflores/utils/ferrors"
+)
+
+// Kind is the kind of an error, as told by its marker method.
+type Kind int
+
+const (
+	Unknown Kind = iota
+	BadRequest
+	NotFound
+	Unauthorized
+	Forbidden
+	NotAcceptable
+	RequestTimeout
+	Conflict
+	Gone
+	PreconditionFailed
+	PayloadTooLarge
+	UnsupportedMediaType
+	UnprocessableEntity
+	TooManyRequests
+	InternalServer
+	BadGateway
+	ServiceUnavailable
+	GatewayTimeout
+	Multi
+)
+
+type (
+	badRequest interface {
+		BadRequest() bool
+		GetFields() map[string]error
+	}
+	notFound interface {
+		NotFound() bool
+	}
+	unauthorized interface {
+		Unauthorized() bool
+	}
+	forbidden interface {
+		Forbidden() bool
+	}
+	notAcceptable interface {
+		NotAcceptable() bool
+	}
+	requestTimeout interface {
+		RequestTimeout() bool
+	}
+	conflict interface {
+		Conflict() bool
+	}
+	gone interface {
+		Gone() bool
+	}
+	preconditionFailed interface {
+		PreconditionFailed() bool
+	}
+	payloadTooLarge interface {
+		PayloadTooLarge() bool
+	}
+	unsupportedMediaType interface {
+		UnsupportedMediaType() bool
+	}
+	unprocessableEntity interface {
+		UnprocessableEntity() bool
+	}
+	tooManyRequests interface {
+		TooManyRequests() bool
+	}
+	internalServer interface {
+		InternalServer() bool
+	}
+	badGateway interface {
+		BadGateway() bool
+	}
+	serviceUnavailable interface {
+		ServiceUnavailable() bool
+	}
+	gatewayTimeout interface {
+		GatewayTimeout() bool
+	}
+)
+
+// Interfaces of the data carried by the ferrors kinds.
+type (
+	Fielder interface {
+		GetFields() map[string]error
+	}
+	RetryAfterer interface {
+		RetryAfter() time.Duration
+	}
+	Coder interface {
+		Code() string
+	}
+	Detailer interface {
+		Details() map[string]interface{}
+	}
+	Messager interface {
+		Msg() string
+	}
+	MessageKeyer interface {
+		MessageKey() (string, map[string]interface{})
+	}
+	Multier interface {
+		Multi() bool
+		Partial() bool
+		Items() []ferrors.ItemError
+	}
+)
+
+// Of returns the kind of err itself, without looking into the errors it wraps.
+func Of(err error) Kind {
+	switch err.(type) {
+	case Multier:
+		return Multi
+	case badRequest:
+		return BadRequest
+	case notFound:
+		return NotFound
+	case unauthorized:
+		return Unauthorized
+	case forbidden:
+		return Forbidden
+	case notAcceptable:
+		return NotAcceptable
+	case requestTimeout:
+		return RequestTimeout
+	case conflict:
+		return Conflict
+	case gone:
+		return Gone
+	case preconditionFailed:
+		return PreconditionFailed
+	case payloadTooLarge:
+		return PayloadTooLarge
+	case unsupportedMediaType:
+		return UnsupportedMediaType
+	case unprocessableEntity:
+		return UnprocessableEntity
+	case tooManyRequests:
+		return TooManyRequests
+	case internalServer:
+		return InternalServer
+	case badGateway:
+		return BadGateway
+	case serviceUnavailable:
+		return ServiceUnavailable
+	case gatewayTimeout:
+		return GatewayTimeout
+	default:
+		return Unknown
+	}
+}
+
+// maxChain bounds the walk of an error chain.
+const maxChain = 100
+
+// Chain lists err and the errors it wraps, through the standard Unwrap
+// methods and the pkg/errors Cause, from the outermost to the innermost.
+// The walk stops at multi errors, whose items are independent errors.
+func Chain(err error) []error {
+	var errs []error
+	var walk func(err error)
+	walk = func(err error) {
+		for err != nil && len(errs) < maxChain {
+			errs = append(errs, err)
+			switch e := err.(type) {
+			case Multier:
+				return
+			case interface{ Unwrap() error }:
+				err = e.Unwrap()
+			case interface{ Unwrap() []error }:
+				for _, inner := range e.Unwrap() {
+					walk(inner)
+				}
+				return
+			case interface{ Cause() error }:
+				err = e.Cause()
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return errs
+}
+
+// StackTrace returns the stack trace of the innermost error of the chain that has one.
+func StackTrace(err error) string {
+	type stackTracer interface {
+		StackTrace() errors.StackTrace
+	}
+	errs := Chain(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		if st, ok := errs[i].(stackTracer); ok {
+			return fmt.Sprintf("%+v", st.StackTrace())
+		}
+	}
+	return ""
+}
+
+// WithCode returns e with code, when it is not empty, such as the code sent by an upstream.
+func WithCode[E interface{ WithCode(string) E }](e E, code string) E {
+	if code == "" {
+		return e
+	}
+	return e.WithCode(code)
+}
@@ -0,0 +1,230 @@
+// Package webhook signs outgoing webhook bodies and verifies incoming ones
+// with HMAC-SHA256, using a Stripe like signature header:
+//
+//	Webhook-Signature: t=1700000000,v1=5257a869e7...,v1=6ffbb59b2...
+//
+// The signed payload is the timestamp, a dot and the raw body.
+// One v1 signature is sent for each active secret, so secrets can be rotated
+// by adding the new one to the signer and the verifiers before removing the old one.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felipeflores/utils/ferrors"
+	"github.com/felipeflores/utils/httpclient"
+)
+
+// DefaultHeader is the header carrying the signature.
+const DefaultHeader = "Webhook-Signature"
+
+// DefaultTolerance is the maximum age of a signature.
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredSignature = errors.New("webhook signature timestamp is outside the tolerance")
+	ErrReplayedRequest  = errors.New("webhook signature was already used")
+)
+
+// Signer signs outgoing webhook bodies.
+type Signer struct {
+	secrets [][]byte
+	header  string
+}
+
+// NewSigner creates a signer that signs with every secret.
+func NewSigner(secrets ...string) *Signer {
+	return &Signer{
+		secrets: toBytes(secrets),
+		header:  DefaultHeader,
+	}
+}
+
+// WithHeader changes the header carrying the signature.
+func (s *Signer) WithHeader(header string) *Signer {
+	s.header = header
+	return s
+}
+
+// Sign returns the signature header value of body at t.
+func (s *Signer) Sign(body []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	parts := []string{"t=" + timestamp}
+	for _, secret := range s.secrets {
+		parts = append(parts, "v1="+hex.EncodeToString(sign(secret, timestamp, body)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Interceptor returns an httpclient interceptor that signs every request body.
+// Signed bodies are sent uncompressed, even with httpclient.WithGzipRequests,
+// so the receiver verifies the same bytes that were signed.
+func (s *Signer) Interceptor() httpclient.Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := readBody(req)
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(httpclient.SkipRequestCompression(req.Context()))
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req.Header.Set(s.header, s.Sign(body, time.Now()))
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Verifier verifies incoming webhook signatures, rejecting expired and replayed ones.
+// It implements httpmiddleware.SignatureVerifier.
+type Verifier struct {
+	secrets   [][]byte
+	header    string
+	tolerance time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewVerifier creates a verifier accepting signatures made with any of the secrets.
+func NewVerifier(secrets ...string) *Verifier {
+	return &Verifier{
+		secrets:   toBytes(secrets),
+		header:    DefaultHeader,
+		tolerance: DefaultTolerance,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// WithHeader changes the header carrying the signature.
+func (v *Verifier) WithHeader(header string) *Verifier {
+	v.header = header
+	return v
+}
+
+// WithTolerance changes the maximum age of a signature.
+func (v *Verifier) WithTolerance(tolerance time.Duration) *Verifier {
+	v.tolerance = tolerance
+	return v
+}
+
+// Verify checks the signature header of body.
+// Every failure is returned as ferrors.ErrUnauthorized.
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	value := header.Get(v.header)
+	if value == "" {
+		return ferrors.NewUnauthorized(ErrMissingSignature)
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = val
+		case "v1":
+			if sig, err := hex.DecodeString(val); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ferrors.NewUnauthorized(ErrInvalidSignature)
+	}
+	now := time.Now()
+	signedAt := time.Unix(seconds, 0)
+	if now.Sub(signedAt) > v.tolerance || signedAt.Sub(now) > v.tolerance {
+		return ferrors.NewUnauthorized(ErrExpiredSignature)
+	}
+
+	if !v.matches(timestamp, body, signatures) {
+		return ferrors.NewUnauthorized(ErrInvalidSignature)
+	}
+	if !v.firstUse(replayKey(timestamp, body), signedAt.Add(v.tolerance), now) {
+		return ferrors.NewUnauthorized(ErrReplayedRequest)
+	}
+	return nil
+}
+
+func (v *Verifier) matches(timestamp string, body []byte, signatures [][]byte) bool {
+	for _, secret := range v.secrets {
+		expected := sign(secret, timestamp, body)
+		for _, sig := range signatures {
+			if hmac.Equal(expected, sig) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstUse records the key of a signed request until it expires and reports
+// whether it was not seen before.
+func (v *Verifier) firstUse(key string, expiry, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for k, exp := range v.seen {
+		if now.After(exp) {
+			delete(v.seen, k)
+		}
+	}
+	if _, ok := v.seen[key]; ok {
+		return false
+	}
+	v.seen[key] = expiry
+	return true
+}
+
+// replayKey identifies a signed request by its timestamp and body hash, so that
+// adding or reordering v1 signatures in the header does not make it new.
+func replayKey(timestamp string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return timestamp + "." + hex.EncodeToString(sum[:])
+}
+
+func sign(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return ioutil.ReadAll(body)
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return ioutil.ReadAll(req.Body)
+}
+
+func toBytes(secrets []string) [][]byte {
+	b := make([][]byte, 0, len(secrets))
+	for _, s := range secrets {
+		b = append(b, []byte(s))
+	}
+	return b
+}